@@ -1,22 +1,34 @@
 package main
 
 import (
+	"context"
+	"cscan/internal/common/logger"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"cscan/internal/co"
-	"cscan/internal/co/zone"
 	"cscan/internal/common/banner"
 	"cscan/internal/common/config"
+	cerrors "cscan/internal/common/errors"
 	"cscan/internal/common/excel"
+	"cscan/internal/common/export"
 	"cscan/internal/common/model"
 	"cscan/internal/cse"
-	"cscan/internal/cse/fofa"
-	"cscan/internal/cse/hunter"
-	"cscan/internal/cse/quake"
+
+	// 注册搜索引擎，新增引擎只需在此导入
+	_ "cscan/internal/co/zone"
+	_ "cscan/internal/cse/censys"
+	_ "cscan/internal/cse/fofa"
+	_ "cscan/internal/cse/hunter"
+	_ "cscan/internal/cse/quake"
+	_ "cscan/internal/cse/shodan"
+	_ "cscan/internal/cse/zoomeye"
 )
 
 // 版本信息
@@ -25,9 +37,6 @@ const (
 )
 
 func main() {
-	// 打印 banner
-	banner.PrintBanner()
-
 	// 检查配置文件是否存在
 	if _, err := os.Stat("config.json"); os.IsNotExist(err) {
 		// 配置文件不存在，创建默认配置
@@ -36,35 +45,65 @@ func main() {
 			FofaEmail:    "your-fofa-email",
 			FofaAPIKey:   "your-fofa-key",
 			QuakeAPIKey:  "your-quake-key",
+			ShodanAPIKey: "your-shodan-key",
+			CensysAPIID:  "your-censys-id",
+			CensysSecret: "your-censys-secret",
+			ZoomEyeKey:   "your-zoomeye-key",
 			ZoneAPIKey:   "your-zone-key",
 			MaxPage:      10,
 			PageSize:     100,
+			CacheDir:     ".cscan_cache",
+			CacheTTL:     24,
+			CacheMaxSize: 512,
 		}
 
 		if err := config.Save("config.json", defaultConfig); err != nil {
-			fmt.Printf("创建配置文件失败: %v\n", err)
-			fmt.Println("请手动创建 config.json 文件并填写以下内容:")
-			fmt.Println(`{
+			logger.Printf("创建配置文件失败: %v\n", err)
+			logger.Println("请手动创建 config.json 文件并填写以下内容:")
+			logger.Println(`{
     "hunter_api_key": "your-hunter-key",
     "fofa_email": "your-fofa-email",
     "fofa_api_key": "your-fofa-key",
     "quake_api_key": "your-quake-key",
+    "shodan_api_key": "your-shodan-key",
+    "censys_api_id": "your-censys-id",
+    "censys_api_secret": "your-censys-secret",
+    "zoomeye_api_key": "your-zoomeye-key",
     "zone_api_key": "your-zone-key",
     "max_page": 10,
-    "page_size": 100
+    "page_size": 100,
+    "cache_dir": ".cscan_cache",
+    "cache_ttl_hours": 24,
+    "cache_max_mb": 512
 }`)
 			os.Exit(1)
 		}
-		fmt.Println("已创建默认配置文件 config.json，请修改配置后重新运行程序")
+		logger.Println("已创建默认配置文件 config.json，请修改配置后重新运行程序")
 		os.Exit(0)
 	}
 
 	// 定义命令行参数
 	var (
-		module     = flag.String("m", "", "模块选择 (cse/co)")
-		filename   = flag.String("f", "target.txt", "输入文件路径 (txt格式)")
-		outputFile = flag.String("o", "results.xlsx", "输出文件路径 (xlsx格式)")
-		version    = flag.Bool("v", false, "显示版本信息")
+		module      = flag.String("m", "", "模块选择 (cse/co)")
+		filename    = flag.String("f", "target.txt", "输入文件路径 (txt/csv/xlsx/json格式)")
+		outputFile  = flag.String("o", "results.xlsx", "输出文件路径，可用逗号分隔多个，- 表示标准输出")
+		format      = flag.String("format", "", "输出格式，可用逗号分隔多个 (xlsx/json/jsonl/csv/md/html/hostport)")
+		version     = flag.Bool("v", false, "显示版本信息")
+		timeout     = flag.Duration("timeout", 0, "运行超时时间 (如 30m，0 表示不限制)")
+		resume      = flag.Bool("resume", false, "从断点日志恢复未完成的 cse 扫描")
+		journal     = flag.String("journal", "", "断点日志路径，指定后记录断点日志 (-resume 时默认: 输出文件名.journal)")
+		noCache     = flag.Bool("no-cache", false, "不使用本地响应缓存")
+		refresh     = flag.Bool("refresh", false, "忽略已有缓存，重新请求并更新缓存")
+		skipQuota   = flag.Bool("skip-quota", false, "运行前不查询剩余额度")
+		strictQuota = flag.Bool("strict-quota", false, "计划消耗超过剩余额度时中止运行")
+		engineList  = flag.String("engines", "", "逗号分隔的引擎列表 (如 fofa,quake)")
+		rawQuery    = flag.String("q", "", "直接使用引擎原生查询语句搜索 (仅 cse)")
+		rawMode     = flag.Bool("raw", false, "输入文件每行为一条原生查询语句 (仅 cse)")
+		qlQuery     = flag.String("ql", "", "使用统一查询语言搜索，自动转换为各引擎的语法")
+		normalize   = flag.String("normalize", excel.NormalizeApex, "域名目标规范化方式 (host/apex/both)")
+		rejectsFile = flag.String("rejects", "", "将输入文件中无法识别而被丢弃的内容写入该文件")
+		sheet       = flag.String("sheet", "", "读取的 XLSX 工作表 (默认: 第一个工作表)")
+		column      = flag.String("column", "", "读取的列名 (CSV/XLSX 表头或 JSON 字段名)")
 	)
 
 	// 自定义 Usage 信息
@@ -75,20 +114,80 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  -m string\t模块选择 (必需参数)\n")
 		fmt.Fprintf(os.Stderr, "    \t\tcse: 网络空间测绘引擎\n")
 		fmt.Fprintf(os.Stderr, "    \t\tco:  公司情报搜索\n")
-		fmt.Fprintf(os.Stderr, "  -f string\t输入文件路径 (默认: target.txt)\n")
-		fmt.Fprintf(os.Stderr, "  -o string\t输出文件路径 (默认: results.xlsx)\n")
+		fmt.Fprintf(os.Stderr, "  -f string\t输入文件路径，支持 txt/csv/xlsx/json，- 表示从标准输入读取 (默认: target.txt)\n")
+		fmt.Fprintf(os.Stderr, "  -sheet string\t读取的 XLSX 工作表 (默认: 第一个工作表)\n")
+		fmt.Fprintf(os.Stderr, "  -column string\t读取的列，CSV/XLSX 为表头名称 (XLSX 也可用列号如 B)，JSON 为字段名\n")
+		fmt.Fprintf(os.Stderr, "    \t\t未指定时读取所有单元格\n")
+		fmt.Fprintf(os.Stderr, "  -o string\t输出文件路径，可用逗号分隔多个，- 表示输出到标准输出，日志改为输出到标准错误 (默认: results.xlsx)\n")
+		fmt.Fprintf(os.Stderr, "  -format string\t输出格式，可用逗号分隔多个，未指定时按扩展名识别 (标准输出默认为 jsonl)\n")
+		fmt.Fprintf(os.Stderr, "    \t\txlsx:     Excel 表格\n")
+		fmt.Fprintf(os.Stderr, "    \t\tjson:     JSON 数组\n")
+		fmt.Fprintf(os.Stderr, "    \t\tjsonl:    每行一个 JSON 对象\n")
+		fmt.Fprintf(os.Stderr, "    \t\tcsv:      CSV 表格 (带 BOM，可直接用 Excel 打开)\n")
+		fmt.Fprintf(os.Stderr, "    \t\tmd:       Markdown 表格\n")
+		fmt.Fprintf(os.Stderr, "    \t\thtml:     包含按引擎、端口统计的 HTML 报告\n")
+		fmt.Fprintf(os.Stderr, "    \t\thostport: 每行一个 host:port (扩展名 .txt)\n")
+		fmt.Fprintf(os.Stderr, "  -timeout duration\t运行超时时间，超时后保存已获取的结果 (如 30m)\n")
+		fmt.Fprintf(os.Stderr, "  -resume\t记录断点日志，并从已有的断点日志恢复未完成的扫描 (仅 cse)\n")
+		fmt.Fprintf(os.Stderr, "  -journal string\t断点日志路径，指定后记录断点日志 (-resume 时默认: 输出文件名.journal)\n")
+		fmt.Fprintf(os.Stderr, "  -no-cache\t不使用本地响应缓存 (仅 cse)\n")
+		fmt.Fprintf(os.Stderr, "  -refresh\t忽略已有缓存，重新请求并更新缓存 (仅 cse)\n")
+		fmt.Fprintf(os.Stderr, "  -skip-quota\t运行前不查询剩余额度\n")
+		fmt.Fprintf(os.Stderr, "  -strict-quota\t计划消耗超过剩余额度时中止运行\n")
+		fmt.Fprintf(os.Stderr, "  -engines string\t逗号分隔的引擎列表，等同于子模块参数 (如 fofa,quake)\n")
+		fmt.Fprintf(os.Stderr, "  -q string\t直接使用引擎原生查询语句搜索，可用 \"引擎名:\" 前缀指定引擎 (仅 cse)\n")
+		fmt.Fprintf(os.Stderr, "  -raw\t\t输入文件每行为一条原生查询语句 (仅 cse)\n")
+		fmt.Fprintf(os.Stderr, "  -normalize string\t域名目标规范化方式 (默认: apex)\n")
+		fmt.Fprintf(os.Stderr, "    \t\tapex: 只搜索可注册域名及其子域名，如 vpn.corp.example.com -> example.com\n")
+		fmt.Fprintf(os.Stderr, "    \t\thost: 只精确搜索完整主机名\n")
+		fmt.Fprintf(os.Stderr, "    \t\tboth: 同时搜索可注册域名和完整主机名\n")
+		fmt.Fprintf(os.Stderr, "  -rejects string\t将输入文件中无法识别而被丢弃的内容写入该文件\n")
+		fmt.Fprintf(os.Stderr, "  -ql string\t使用统一查询语言搜索，自动转换为各引擎的语法\n")
+		fmt.Fprintf(os.Stderr, "    \t\t可用字段: %s\n", strings.Join(cse.QueryFieldNames(), ", "))
 		fmt.Fprintf(os.Stderr, "  -v\t\t显示版本信息\n")
+		fmt.Fprintf(os.Stderr, "\nSubmodules (可用逗号选择多个，如 hunter,fofa):\n")
+		fmt.Fprintf(os.Stderr, "  cse:\n")
+		for _, engine := range cse.Engines() {
+			fmt.Fprintf(os.Stderr, "    %-10s%s\n", engine.Name, engine.Description)
+		}
+		fmt.Fprintf(os.Stderr, "  co:\n")
+		for _, engine := range co.Engines() {
+			fmt.Fprintf(os.Stderr, "    %-10s%s\n", engine.Name, engine.Description)
+		}
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  cscan -m cse -f targets.txt -o results.xlsx\t运行所有网络空间测绘引擎\n")
 		fmt.Fprintf(os.Stderr, "  cscan -m cse fofa -f targets.txt -o fofa.xlsx\t仅运行 Fofa 引擎\n")
 		fmt.Fprintf(os.Stderr, "  cscan -m cse hunter -o hunter_results\t\t仅运行 Hunter 引擎\n")
+		fmt.Fprintf(os.Stderr, "  cscan -m cse hunter,quake -f targets.txt\t运行 Hunter 和 Quake 引擎\n")
+		fmt.Fprintf(os.Stderr, "  cscan -m cse -engines fofa,quake -f targets.txt\t同上，使用 -engines 参数\n")
+		fmt.Fprintf(os.Stderr, "  cscan -m cse fofa -q 'title=\"xxx\" && country=\"CN\"'\t使用 FOFA 原生查询语句搜索\n")
+		fmt.Fprintf(os.Stderr, "  cscan -m cse -raw -f queries.txt\t\t按文件中的原生查询语句搜索\n")
+		fmt.Fprintf(os.Stderr, "  cscan -m cse -ql 'title:\"login\" AND port:8443 AND country:CN'\t统一查询语言搜索所有引擎\n")
+		fmt.Fprintf(os.Stderr, "  cscan -m cse -f clients.xlsx -sheet 资产 -column 域名\t读取 Excel 工作表中的指定列\n")
+		fmt.Fprintf(os.Stderr, "  subfinder -d example.com | cscan -m cse -f - -o - -format hostport | httpx\t在管道中使用\n")
 		fmt.Fprintf(os.Stderr, "  cscan -m co -f companies.txt -o company_assets\t运行公司情报搜索\n")
 	}
 
 	// 创建一个新的 FlagSet 来处理子模块
 	subflags := flag.NewFlagSet("submodule", flag.ExitOnError)
-	subflags.StringVar(filename, "f", "target.txt", "输入文件路径 (txt格式)")
-	subflags.StringVar(outputFile, "o", "results.xlsx", "输出文件路径 (xlsx格式)")
+	subflags.StringVar(filename, "f", "target.txt", "输入文件路径 (txt/csv/xlsx/json格式)")
+	subflags.StringVar(outputFile, "o", "results.xlsx", "输出文件路径，可用逗号分隔多个，- 表示标准输出")
+	subflags.StringVar(format, "format", "", "输出格式，可用逗号分隔多个 (xlsx/json/jsonl/csv/md/html/hostport)")
+	subflags.DurationVar(timeout, "timeout", 0, "运行超时时间 (如 30m，0 表示不限制)")
+	subflags.BoolVar(resume, "resume", false, "从断点日志恢复未完成的 cse 扫描")
+	subflags.StringVar(journal, "journal", "", "断点日志路径，指定后记录断点日志 (-resume 时默认: 输出文件名.journal)")
+	subflags.BoolVar(noCache, "no-cache", false, "不使用本地响应缓存")
+	subflags.BoolVar(refresh, "refresh", false, "忽略已有缓存，重新请求并更新缓存")
+	subflags.BoolVar(skipQuota, "skip-quota", false, "运行前不查询剩余额度")
+	subflags.BoolVar(strictQuota, "strict-quota", false, "计划消耗超过剩余额度时中止运行")
+	subflags.StringVar(engineList, "engines", "", "逗号分隔的引擎列表 (如 fofa,quake)")
+	subflags.StringVar(rawQuery, "q", "", "直接使用引擎原生查询语句搜索 (仅 cse)")
+	subflags.BoolVar(rawMode, "raw", false, "输入文件每行为一条原生查询语句 (仅 cse)")
+	subflags.StringVar(qlQuery, "ql", "", "使用统一查询语言搜索，自动转换为各引擎的语法")
+	subflags.StringVar(normalize, "normalize", excel.NormalizeApex, "域名目标规范化方式 (host/apex/both)")
+	subflags.StringVar(rejectsFile, "rejects", "", "将输入文件中无法识别而被丢弃的内容写入该文件")
+	subflags.StringVar(sheet, "sheet", "", "读取的 XLSX 工作表 (默认: 第一个工作表)")
+	subflags.StringVar(column, "column", "", "读取的列名 (CSV/XLSX 表头或 JSON 字段名)")
 
 	// 首先解析主要参数
 	flag.Parse()
@@ -97,42 +196,73 @@ func main() {
 	var submodule string
 	args := flag.Args()
 	if len(args) > 0 {
-		// 检查第一个非标志参数是否是子模块
-		validSubmodule := false
-		switch *module {
-		case "cse":
-			if args[0] == "hunter" || args[0] == "fofa" || args[0] == "quake" {
-				validSubmodule = true
-			}
-		case "co":
-			if args[0] == "zone" {
-				validSubmodule = true
-			}
-		}
-
-		if validSubmodule {
+		// 第一个非标志参数是子模块，可用逗号分隔多个引擎
+		if !strings.HasPrefix(args[0], "-") && strings.TrimSpace(args[0]) != "" {
 			submodule = args[0]
 			// 如果有子模块，解析剩余参数
 			if len(args) > 1 {
 				if err := subflags.Parse(args[1:]); err != nil {
-					fmt.Printf("解析子模块参数错误: %v\n", err)
+					logger.Printf("解析子模块参数错误: %v\n", err)
 					os.Exit(1)
 				}
 			}
 		}
 	}
 
+	outputs, err := export.ParseOutputs(*outputFile, *format)
+	if err != nil {
+		logger.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+	// -o - 时结果写入标准输出，进度和日志改为写入标准错误，避免混入管道
+	for _, output := range outputs {
+		if output.Path == "-" {
+			logger.SetOutput(os.Stderr)
+		}
+	}
+
+	// 打印 banner
+	banner.PrintBanner()
+
+	// -engines 与子模块参数等价，同时指定时合并
+	if *engineList != "" {
+		if submodule != "" {
+			submodule += ","
+		}
+		submodule += *engineList
+	}
+	if submodule != "" {
+		var err error
+		switch *module {
+		case "cse":
+			_, err = cse.SelectEngines(submodule, nil)
+		case "co":
+			_, err = co.SelectEngines(submodule)
+		}
+		if err != nil {
+			logger.Println(err)
+			os.Exit(1)
+		}
+	}
+
 	// 检查版本参数
 	if *version {
-		fmt.Printf("CScan %s\n", Version)
-		fmt.Println("网络空间资产搜索工具")
+		logger.Printf("CScan %s\n", Version)
+		logger.Println("网络空间资产搜索工具")
 		os.Exit(0)
 	}
 
+	switch *normalize {
+	case excel.NormalizeApex, excel.NormalizeHost, excel.NormalizeBoth:
+	default:
+		logger.Printf("错误: 无效的 -normalize 参数 %s，可选 host、apex、both\n", *normalize)
+		os.Exit(1)
+	}
+
 	// 检查必需参数
 	if *module == "" {
-		fmt.Println("错误: 必须指定模块类型 (-m)")
-		fmt.Println("可用模块: cse (网络空间测绘引擎) 或 co (公司情报)")
+		logger.Println("错误: 必须指定模块类型 (-m)")
+		logger.Println("可用模块: cse (网络空间测绘引擎) 或 co (公司情报)")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -140,151 +270,442 @@ func main() {
 	// 检查并加载配置
 	cfg, err := config.LoadOrCreate("config.json")
 	if err != nil {
-		fmt.Printf("加载配置失败: %v\n", err)
+		logger.Printf("加载配置失败: %v\n", err)
 		os.Exit(1)
 	}
 
 	// 检查配置是否完整
-	if err := validateConfig(cfg, *module); err != nil {
-		fmt.Printf("配置验证失败: %v\n", err)
+	if err := validateConfig(cfg, *module, submodule); err != nil {
+		logger.Printf("配置验证失败: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 检查输入文件
-	if _, err := os.Stat(*filename); os.IsNotExist(err) {
-		fmt.Printf("错误: 输入文件 %s 不存在\n", *filename)
-		os.Exit(1)
-	}
+	// 检查输入文件，使用 -q 或 -ql 时不需要输入文件
+	if *rawQuery == "" && *qlQuery == "" {
+		if _, err := os.Stat(*filename); os.IsNotExist(err) && *filename != "-" {
+			logger.Printf("错误: 输入文件 %s 不存在\n", *filename)
+			os.Exit(1)
+		}
 
-	// 检查输入文件后缀
-	if !strings.HasSuffix(*filename, ".txt") {
-		fmt.Println("错误: 输入文件必须是 .txt 格式")
-		os.Exit(1)
+		// 检查输入文件后缀
+		if !excel.IsSupportedInput(*filename) {
+			logger.Printf("错误: 不支持的输入文件格式，支持: %s\n", strings.Join(excel.SupportedInputs, ", "))
+			os.Exit(1)
+		}
+	}
+	inputOpts := excel.InputOptions{Sheet: *sheet, Column: *column}
+
+	// Ctrl-C / SIGTERM 时取消进行中的请求，已获取的结果仍会被保存
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
-
-	// 处理输出文件名
-	*outputFile = ensureXLSXExtension(*outputFile)
 
 	// 根据模块类型初始化不同的扫描器
 	switch *module {
 	case "cse":
-		var scanners []cse.Scanner
-		switch submodule {
-		case "":
-			// 使用所有扫描器
-			scanners = []cse.Scanner{
-				hunter.NewScanner(cfg.HunterAPIKey),
-				fofa.NewScanner(cfg.FofaEmail, cfg.FofaAPIKey),
-				quake.NewScanner(cfg.QuakeAPIKey),
-			}
-		case "hunter":
-			scanners = []cse.Scanner{hunter.NewScanner(cfg.HunterAPIKey)}
-		case "fofa":
-			scanners = []cse.Scanner{fofa.NewScanner(cfg.FofaEmail, cfg.FofaAPIKey)}
-		case "quake":
-			scanners = []cse.Scanner{quake.NewScanner(cfg.QuakeAPIKey)}
-		default:
-			fmt.Printf("未知的子模块: %s\n", submodule)
-			fmt.Println("可用子模块: hunter, fofa, quake")
+		// 未指定子模块时使用所有已配置 Key 的引擎
+		engines, err := cse.SelectEngines(submodule, cfg)
+		if err != nil {
+			logger.Println(err)
 			os.Exit(1)
 		}
+		if submodule == "" {
+			for _, engine := range cse.Engines() {
+				if !engine.IsConfigured(cfg) {
+					logger.Printf("跳过未配置 Key 的引擎: %s\n", engine.Name)
+				}
+			}
+		}
+		scanners := cse.NewScanners(engines, cfg)
+
+		// 使用本地缓存包装扫描器，避免重复消耗额度
+		if !*noCache {
+			cache, err := cse.NewCache(cfg.CacheDir, time.Duration(cfg.CacheTTL)*time.Hour, int64(cfg.CacheMaxSize)<<20, *refresh)
+			if err != nil {
+				logger.Printf("初始化缓存失败: %v\n", err)
+				return
+			}
+			if removed, err := cache.Sweep(); err != nil {
+				logger.Printf("清理缓存失败: %v\n", err)
+			} else if removed > 0 {
+				logger.Printf("已清理 %d 个过期或超出大小上限的缓存文件\n", removed)
+			}
+			for i, scanner := range scanners {
+				scanners[i] = cse.NewCachedScanner(scanner, cache)
+			}
+		}
 
 		engine := cse.NewSearchEngine(scanners...)
+		for name, budget := range cfg.Budgets {
+			engine.SetBudget(name, budget.MaxRequests, budget.MaxResults)
+		}
+		for name, policy := range cfg.Retry {
+			engine.SetRetryPolicy(name, cse.RetryPolicy{
+				MaxAttempts: policy.MaxAttempts,
+				BaseDelay:   time.Duration(policy.BaseDelay) * time.Second,
+				MaxDelay:    time.Duration(policy.MaxDelay) * time.Second,
+			})
+		}
 
-		// 从文件读取目标
-		targets, err := readTargets(*filename)
+		// 指定 -journal 或 -resume 时打开断点日志，记录每个 目标 × 引擎 × 页 的完成情况
+		journalPath := *journal
+		if journalPath == "" && *resume {
+			journalPath = outputs[0].Path + ".journal"
+			if outputs[0].Path == "-" {
+				journalPath = "stdout.journal"
+			}
+		}
+		if journalPath != "" {
+			j, err := cse.OpenJournal(journalPath, *resume)
+			if err != nil {
+				logger.Printf("打开断点日志失败: %v\n", err)
+				return
+			}
+			defer j.Close()
+			if *resume {
+				pages, assets := j.Completed()
+				logger.Printf("从断点日志 %s 恢复，已完成 %d 页 (%d 条结果)\n", journalPath, pages, assets)
+			}
+			engine.SetJournal(j)
+		}
+
+		// 读取目标：-ql 统一查询语言、-q 指定的查询语句、-raw 模式下文件中的查询语句，或文件中的 IP/域名
+		var targets []cse.Target
+		switch {
+		case *qlQuery != "":
+			var skipped map[string]error
+			targets, skipped, err = cse.CompileQuery(*qlQuery, engines)
+			for _, engine := range engines {
+				if reason, ok := skipped[engine.Name]; ok {
+					logger.Printf("跳过引擎: %v\n", reason)
+				}
+			}
+		case *rawQuery != "":
+			targets = []cse.Target{cse.ParseQuery(*rawQuery)}
+		case *rawMode:
+			targets, err = excel.ReadQueries(*filename, inputOpts)
+		default:
+			targets, err = readTargets(*filename, *normalize, inputOpts, *rejectsFile)
+		}
 		if err != nil {
-			fmt.Printf("读取目标失败: %v\n", err)
+			logger.Printf("读取目标失败: %v\n", err)
 			return
 		}
-		fmt.Printf("开始处理 %d 个目标\n", len(targets))
+		if *rawQuery != "" || *rawMode {
+			targets = checkQueryEngines(targets, engines)
+		}
+		// 运行前检查各引擎剩余额度
+		if !*skipQuota {
+			enough := true
+			logger.Println("剩余额度:")
+			// 同一引擎的多个扫描器（如 ZoomEye 主机和 Web 搜索）共用一个账号，只查询一次额度，
+			// 计划消耗为该引擎所有扫描器之和
+			planned := make(map[string]int)
+			for _, scanner := range scanners {
+				name := cse.EngineOf(scanner.Name())
+				for _, target := range targets {
+					if target.Engine == "" || target.Engine == name {
+						planned[name] += cfg.MaxPage
+					}
+				}
+			}
+			checked := make(map[string]bool)
+			for _, scanner := range scanners {
+				name := cse.EngineOf(scanner.Name())
+				if checked[name] {
+					continue
+				}
+				checked[name] = true
 
-		// 执行搜索
-		results, err := engine.SearchTargets(targets, cfg.MaxPage, cfg.PageSize)
+				q, ok := cse.AsQuotaScanner(scanner)
+				if !ok {
+					continue
+				}
+				quota, err := q.Quota(ctx)
+				if errors.Is(err, cerrors.ErrQuotaUnknown) {
+					logger.Printf("  %-8s %v\n", scanner.Name(), err)
+					continue
+				}
+				if err != nil {
+					logger.Printf("  %-8s 查询额度失败: %v\n", scanner.Name(), err)
+					continue
+				}
+				requests := planned[name]
+				if budget, ok := budgetFor(cfg, name); ok && budget.MaxRequests > 0 && budget.MaxRequests < requests {
+					requests = budget.MaxRequests
+				}
+				if !checkQuota(scanner.Name(), quota, requests, cfg.PageSize) {
+					enough = false
+				}
+			}
+			if !enough && *strictQuota {
+				logger.Println("剩余额度不足，已中止运行")
+				return
+			}
+		}
+
+		// 结果获取后立即去重写入输出文件，不在内存中保留
+		sink, err := export.Open(outputs, os.Stdout)
 		if err != nil {
-			fmt.Printf("搜索失败: %v\n", err)
+			logger.Printf("创建输出文件失败: %v\n", err)
 			return
 		}
-		fmt.Printf("搜索完成，共获取到 %d 条结果\n", len(results))
+		engine.SetSink(sink)
+
+		logger.Printf("开始处理 %d 个目标\n", len(targets))
+
+		// 执行搜索
+		_, err = engine.SearchTargets(ctx, targets, cfg.MaxPage, cfg.PageSize)
+		printUsage(engine.Usage())
+		printKeyUsage(scanners)
+		if err != nil {
+			reportSearchError(err, sink.Total())
+		} else {
+			logger.Printf("搜索完成，共获取到 %d 条结果\n", sink.Total())
+		}
 
-		// 保存结果
-		if err := saveResults(results, *outputFile); err != nil {
-			fmt.Printf("保存结果失败: %v\n", err)
+		// 完成写入，已获取的结果在出错或中断时同样会保存
+		if err := sink.Close(); err != nil {
+			logger.Printf("保存结果失败: %v\n", err)
 			return
 		}
-		fmt.Printf("结果已保存到 %s\n", *outputFile)
+		if ctx.Err() != nil {
+			if journalPath != "" {
+				logger.Printf("可使用 -resume 从断点日志 %s 继续扫描\n", journalPath)
+			} else {
+				logger.Println("提示: 使用 -resume 或 -journal 运行时会记录断点日志，中断后可以继续扫描")
+			}
+		}
 
 	case "co":
-		switch submodule {
-		case "", "zone":
-			// 使用 zone scanner
-			scanner := zone.NewScanner(cfg.ZoneAPIKey)
-			companyScanner := co.NewCompanyScanner(scanner)
-
-			// 从文件读取公司名称
-			companies, err := readCompanies(*filename)
-			if err != nil {
-				fmt.Printf("读取公司名称失败: %v\n", err)
+		engines, err := co.SelectEngines(submodule)
+		if err != nil {
+			logger.Println(err)
+			os.Exit(1)
+		}
+		// 统一查询语言直接搜索，不按公司名称展开
+		if *qlQuery != "" {
+			results, err := co.SearchQL(ctx, *qlQuery, engines, cfg, cfg.PageSize)
+			if err != nil && !reportSearchError(err, len(results)) {
 				return
 			}
-
-			// 执行搜索
-			results, err := companyScanner.SearchCompanies(companies, cfg.MaxPage, cfg.PageSize)
-			if err != nil {
-				fmt.Printf("搜索失败: %v\n", err)
+			if err := export.Save(results, outputs, os.Stdout); err != nil {
+				logger.Printf("保存结果失败: %v\n", err)
 				return
 			}
+			return
+		}
 
-			// 保存结果
-			if err := saveResults(results, *outputFile); err != nil {
-				fmt.Printf("保存结果失败: %v\n", err)
+		scanners := co.NewScanners(engines, cfg)
+		companyScanner := co.NewCompanyScanner(scanners...)
+
+		// 从文件读取公司名称
+		companies, err := readCompanies(*filename, inputOpts, *rejectsFile)
+		if err != nil {
+			logger.Printf("读取公司名称失败: %v\n", err)
+			return
+		}
+
+		// 运行前检查剩余额度
+		if !*skipQuota {
+			enough := true
+			logger.Println("剩余额度:")
+			for _, scanner := range scanners {
+				q, ok := scanner.(co.QuotaScanner)
+				if !ok {
+					continue
+				}
+				quota, err := q.Quota(ctx)
+				if err != nil {
+					logger.Printf("  %-8s 查询额度失败: %v\n", scanner.Name(), err)
+					continue
+				}
+				perCompany := 1
+				if e, ok := scanner.(co.RequestEstimator); ok {
+					perCompany = e.RequestsPerCompany()
+				}
+				if !checkQuota(scanner.Name(), quota, len(companies)*perCompany, cfg.PageSize) {
+					enough = false
+				}
+			}
+			if !enough && *strictQuota {
+				logger.Println("剩余额度不足，已中止运行")
 				return
 			}
-			fmt.Printf("结果已保存到 %s\n", *outputFile)
+		}
 
-		default:
-			fmt.Printf("未知的子模块: %s\n", submodule)
-			fmt.Println("可用子模块: zone")
-			os.Exit(1)
+		// 执行搜索
+		results, err := companyScanner.SearchCompanies(ctx, companies, cfg.MaxPage, cfg.PageSize)
+		printCompanyKeyUsage(scanners)
+		if err != nil && !reportSearchError(err, len(results)) {
+			return
+		}
+
+		// 保存结果，xlsx 按数据类型分工作表输出
+		if err := export.Save(results, co.Outputs(outputs), os.Stdout); err != nil {
+			logger.Printf("保存结果失败: %v\n", err)
+			return
 		}
 
 	default:
-		fmt.Printf("未知的模块类型: %s\n", *module)
-		fmt.Println("可用模块: cse (网络空间测绘引擎) 或 co (公司情报)")
+		logger.Printf("未知的模块类型: %s\n", *module)
+		logger.Println("可用模块: cse (网络空间测绘引擎) 或 co (公司情报)")
 		os.Exit(1)
 	}
 }
 
-func readTargets(filename string) ([]cse.Target, error) {
-	return excel.ReadTargets(filename)
+// reportSearchError 输出搜索错误，返回是否仍需保存已获取的结果
+func reportSearchError(err error, collected int) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		logger.Printf("搜索已中断，保存已获取的 %d 条结果\n", collected)
+		return collected > 0
+	}
+	logger.Printf("搜索失败: %v\n", err)
+	return collected > 0
+}
+
+// checkQuota 输出剩余额度，计划消耗超过剩余额度时给出警告并返回 false
+func checkQuota(name string, quota *model.Quota, plannedRequests, pageSize int) bool {
+	planned := plannedRequests
+	if quota.PerResult {
+		planned *= pageSize
+	}
+
+	line := fmt.Sprintf("  %-8s 剩余 %d %s", name, quota.Remaining, quota.Unit)
+	if quota.Detail != "" {
+		line += fmt.Sprintf(" (%s)", quota.Detail)
+	}
+	line += fmt.Sprintf("，本次最多消耗 %d %s", planned, quota.Unit)
+	logger.Println(line)
+
+	if planned > quota.Remaining {
+		logger.Printf("  警告: %s 计划消耗超过剩余额度，结果可能不完整\n", name)
+		return false
+	}
+	return true
 }
 
-func readCompanies(filename string) ([]string, error) {
-	return excel.ReadCompanies(filename)
+// budgetFor 查找引擎的额度配置，name 为引擎的注册名，配置中的键不区分大小写
+func budgetFor(cfg *config.Config, name string) (config.Budget, bool) {
+	for key, budget := range cfg.Budgets {
+		if strings.EqualFold(key, name) {
+			return budget, true
+		}
+	}
+	return config.Budget{}, false
 }
 
-func saveResults(results []model.Asset, filename string) error {
-	return excel.SaveResults(results, filename)
+// printUsage 输出各引擎本次运行的额度使用情况
+func printUsage(usages []cse.Usage) {
+	logger.Println("额度使用情况:")
+	for _, u := range usages {
+		line := fmt.Sprintf("  %-8s 请求 %d 次", u.Name, u.Requests)
+		if u.MaxRequests > 0 {
+			line += fmt.Sprintf("/%d", u.MaxRequests)
+		}
+		line += fmt.Sprintf("，结果 %d 条", u.Results)
+		if u.MaxResults > 0 {
+			line += fmt.Sprintf("/%d", u.MaxResults)
+		}
+		line += fmt.Sprintf("，缓存/断点命中 %d 页", u.CacheHits)
+		if u.Exhausted {
+			line += " (额度已用尽)"
+		}
+		logger.Println(line)
+	}
 }
 
-// ensureXLSXExtension 确保文件名以 .xlsx 结尾
-func ensureXLSXExtension(filename string) string {
-	// 如果没有扩展名，添加 .xlsx
-	if !strings.Contains(filename, ".") {
-		return filename + ".xlsx"
+// printKeyUsage 输出多账号引擎中每个 API Key 的使用情况
+func printKeyUsage(scanners []cse.Scanner) {
+	for _, scanner := range scanners {
+		pool, ok := cse.AsKeyPool(scanner)
+		if !ok {
+			continue
+		}
+		printPoolUsage(scanner.Name(), pool.KeyUsage())
 	}
+}
 
-	// 如果扩展名不是 .xlsx，替换为 .xlsx
-	ext := filepath.Ext(filename)
-	if ext != ".xlsx" {
-		return strings.TrimSuffix(filename, ext) + ".xlsx"
+// printCompanyKeyUsage 输出公司情报引擎中每个 API Key 的使用情况
+func printCompanyKeyUsage(scanners []co.Scanner) {
+	for _, scanner := range scanners {
+		if pool, ok := scanner.(interface{ KeyUsage() []cse.KeyUsage }); ok {
+			printPoolUsage(scanner.Name(), pool.KeyUsage())
+		}
 	}
+}
 
-	return filename
+// printPoolUsage 输出账号池中各账号的请求数、结果数和停用原因
+func printPoolUsage(name string, usage []cse.KeyUsage) {
+	logger.Printf("%s 账号使用情况:\n", name)
+	for _, u := range usage {
+		line := fmt.Sprintf("  %s 请求 %d 次，结果 %d 条", u.Label, u.Requests, u.Results)
+		if u.Exhausted {
+			line += fmt.Sprintf(" (已停用: %s)", u.LastError)
+		}
+		logger.Println(line)
+	}
+}
+
+// checkQueryEngines 过滤指定了未启用引擎的原生查询
+// 未指定引擎的查询会发送给所有所选引擎，各引擎语法不同，选择了多个引擎时给出提示
+func checkQueryEngines(queries []cse.Target, engines []cse.Engine) []cse.Target {
+	enabled := make(map[string]bool)
+	for _, engine := range engines {
+		enabled[engine.Name] = true
+	}
+
+	var kept []cse.Target
+	for _, query := range queries {
+		if query.Engine != "" && !enabled[query.Engine] {
+			logger.Printf("警告: 引擎 %s 未启用，跳过查询: %s\n", query.Engine, query.Value)
+			continue
+		}
+		if query.Engine == "" && len(engines) > 1 {
+			logger.Printf("提示: 查询未指定引擎，将发送给所有所选引擎: %s\n", query.Value)
+		}
+		kept = append(kept, query)
+	}
+	return kept
+}
+
+func readTargets(filename, normalize string, opts excel.InputOptions, rejectsFile string) ([]cse.Target, error) {
+	targets, report, err := excel.ReadTargets(filename, normalize, opts)
+	if err != nil {
+		return nil, err
+	}
+	handleReport(report, rejectsFile)
+	return targets, nil
+}
+
+func readCompanies(filename string, opts excel.InputOptions, rejectsFile string) ([]string, error) {
+	companies, report, err := excel.ReadCompanies(filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	handleReport(report, rejectsFile)
+	return companies, nil
+}
+
+// handleReport 输出输入文件的解析摘要，指定了 rejectsFile 时写入被丢弃的内容
+func handleReport(report *excel.ParseReport, rejectsFile string) {
+	report.PrintSummary()
+	if rejectsFile == "" {
+		return
+	}
+	if err := report.WriteRejects(rejectsFile); err != nil {
+		logger.Printf("写入丢弃记录失败: %v\n", err)
+		return
+	}
+	logger.Printf("丢弃记录已保存到 %s\n", rejectsFile)
 }
 
 // validateConfig 验证配置是否完整
-func validateConfig(cfg *config.Config, moduleType string) error {
+func validateConfig(cfg *config.Config, moduleType, submodule string) error {
 	if cfg.MaxPage <= 0 {
 		return fmt.Errorf("max_page 必须大于 0")
 	}
@@ -292,17 +713,20 @@ func validateConfig(cfg *config.Config, moduleType string) error {
 		return fmt.Errorf("page_size 必须大于 0")
 	}
 
-	// 根据使用的模块检查对应的 API Key
-	if moduleType == "cse" {
-		if cfg.HunterAPIKey == "your-hunter-key" &&
-			cfg.FofaAPIKey == "your-fofa-key" &&
-			cfg.QuakeAPIKey == "your-quake-key" {
-			return fmt.Errorf("请至少配置一个搜索引擎的 API Key")
+	// 根据使用的模块检查所选引擎的 API Key
+	switch moduleType {
+	case "cse":
+		engines, err := cse.SelectEngines(submodule, cfg)
+		if err != nil {
+			return err
 		}
-	} else if moduleType == "co" {
-		if cfg.ZoneAPIKey == "your-zone-key" {
-			return fmt.Errorf("请配置 Zone API Key")
+		return cse.ValidateEngines(engines, cfg, submodule != "")
+	case "co":
+		engines, err := co.SelectEngines(submodule)
+		if err != nil {
+			return err
 		}
+		return co.ValidateEngines(engines, cfg)
 	}
 
 	return nil
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cscan/internal/cse"
+	"reflect"
+	"testing"
+)
+
+func TestCheckQueryEngines(t *testing.T) {
+	lookup := func(names ...string) []cse.Engine {
+		var engines []cse.Engine
+		for _, name := range names {
+			engine, ok := cse.LookupEngine(name)
+			if !ok {
+				t.Fatalf("引擎 %s 未注册", name)
+			}
+			engines = append(engines, engine)
+		}
+		return engines
+	}
+	queries := []cse.Target{
+		cse.ParseQuery(`fofa: title="xxx"`),
+		cse.ParseQuery(`quake: app:"nginx"`),
+		cse.ParseQuery(`hunter: web.title="xxx"`),
+		cse.ParseQuery(`port="8443"`),
+	}
+
+	tests := []struct {
+		name    string
+		engines []cse.Engine
+		want    []string // 保留的查询语句
+	}{
+		{"全部启用", lookup("fofa", "quake", "hunter"), []string{`title="xxx"`, `app:"nginx"`, `web.title="xxx"`, `port="8443"`}},
+		{"跳过未启用引擎的查询", lookup("fofa", "quake"), []string{`title="xxx"`, `app:"nginx"`, `port="8443"`}},
+		{"只启用一个引擎", lookup("hunter"), []string{`web.title="xxx"`, `port="8443"`}},
+		{"未指定引擎的查询总是保留", lookup("shodan"), []string{`port="8443"`}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, query := range checkQueryEngines(queries, tt.engines) {
+			got = append(got, query.Value)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: kept %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 package co
 
 import (
-	"cscan/internal/co/zone"
+	"context"
+	"cscan/internal/common/logger"
 	"cscan/internal/common/model"
-	"fmt"
 	"time"
 )
 
@@ -13,7 +13,21 @@ type Scanner interface {
 	Name() string
 
 	// SearchByCompany 根据公司名称搜索相关资产
-	SearchByCompany(company string, page, size int) (map[string][]model.Asset, error)
+	SearchByCompany(ctx context.Context, company string, page, size int) (map[string][]model.Asset, error)
+}
+
+// QuotaScanner 支持查询账户剩余额度的扫描器
+type QuotaScanner interface {
+	Scanner
+
+	// Quota 返回账户当前的剩余额度
+	Quota(ctx context.Context) (*model.Quota, error)
+}
+
+// RequestEstimator 能够预估每个公司消耗请求次数的扫描器
+type RequestEstimator interface {
+	// RequestsPerCompany 返回搜索一个公司需要的请求次数
+	RequestsPerCompany() int
 }
 
 // CompanyScanner 公司情报扫描器管理器
@@ -29,10 +43,10 @@ func NewCompanyScanner(scanners ...Scanner) *CompanyScanner {
 }
 
 // Search 使用所有可用的扫描器执行搜索
-func (c *CompanyScanner) Search(company string, page, size int) ([]model.Asset, error) {
+func (c *CompanyScanner) Search(ctx context.Context, company string, page, size int) ([]model.Asset, error) {
 	var results []model.Asset
 	for _, scanner := range c.scanners {
-		assetMap, err := scanner.SearchByCompany(company, page, size)
+		assetMap, err := scanner.SearchByCompany(ctx, company, page, size)
 		if err != nil {
 			continue
 		}
@@ -45,39 +59,43 @@ func (c *CompanyScanner) Search(company string, page, size int) ([]model.Asset,
 }
 
 // SearchCompanies 批量搜索公司
-func (c *CompanyScanner) SearchCompanies(companies []string, maxPage, pageSize int) ([]model.Asset, error) {
+// 上下文被取消时停止搜索，并返回已获取的结果及 ctx.Err()
+func (c *CompanyScanner) SearchCompanies(ctx context.Context, companies []string, maxPage, pageSize int) ([]model.Asset, error) {
 	allResults := make(map[string][]model.Asset)
 
+companies:
 	for i, company := range companies {
-		fmt.Printf("处理公司 (%d/%d): %s\n", i+1, len(companies), company)
+		if ctx.Err() != nil {
+			break
+		}
+
+		logger.Printf("处理公司 (%d/%d): %s\n", i+1, len(companies), company)
 
 		for _, scanner := range c.scanners {
 			if scanner == nil {
 				continue
 			}
 
-			fmt.Printf("使用 %s 搜索...\n", scanner.Name())
-			assetMap, err := scanner.SearchByCompany(company, 1, pageSize)
-			if err != nil {
-				fmt.Printf("查询出错: %v\n", err)
-				continue
-			}
-
-			// 合并每种类型的资产
+			logger.Printf("使用 %s 搜索...\n", scanner.Name())
+			assetMap, err := scanner.SearchByCompany(ctx, company, 1, pageSize)
+			// 合并每种类型的资产（取消时也保留已获取的部分）
 			for searchType, assets := range assetMap {
 				allResults[searchType] = append(allResults[searchType], assets...)
 			}
+			if err != nil {
+				if ctx.Err() != nil {
+					break companies
+				}
+				logger.Printf("查询出错: %v\n", err)
+				continue
+			}
 		}
 
 		if i < len(companies)-1 {
-			time.Sleep(2 * time.Second)
-		}
-	}
-
-	// 调用 Zone Scanner 的 SaveResults 方法保存结果
-	if zoneScanner := c.getZoneScanner(); zoneScanner != nil {
-		if err := zoneScanner.SaveResults(allResults, "results.xlsx"); err != nil {
-			return nil, fmt.Errorf("保存结果失败: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(2 * time.Second):
+			}
 		}
 	}
 
@@ -87,15 +105,5 @@ func (c *CompanyScanner) SearchCompanies(companies []string, maxPage, pageSize i
 		flatResults = append(flatResults, assets...)
 	}
 
-	return flatResults, nil
-}
-
-// getZoneScanner 获取 Zone Scanner 实例
-func (c *CompanyScanner) getZoneScanner() *zone.Scanner {
-	for _, scanner := range c.scanners {
-		if s, ok := scanner.(*zone.Scanner); ok {
-			return s
-		}
-	}
-	return nil
+	return flatResults, ctx.Err()
 }
@@ -0,0 +1,108 @@
+package co
+
+import (
+	"cscan/internal/common/excel"
+	"cscan/internal/common/export"
+	"cscan/internal/common/model"
+	"io"
+	"strings"
+)
+
+// emptySheetMessage 某类数据没有结果时在工作表中显示的说明
+const emptySheetMessage = "当前API Key无此类型数据访问权限或未找到相关数据"
+
+// typeSheets 公司情报各数据类型的工作表列，顺序与 0.zone 的查询顺序一致
+var typeSheets = []struct {
+	Type    string
+	Headers []string
+	Row     func(model.Asset) []string
+}{
+	{"site", []string{"IP", "Domain", "Port", "Service", "Title", "Status", "Location", "Company", "UpdateTime"},
+		func(a model.Asset) []string {
+			return []string{a.IP, a.Domain, a.Port, a.Service, a.Title, a.StatusCode, a.Location, a.ICPOrg, a.UpdatedAt}
+		}},
+	{"apk", []string{"Name", "Package", "Version", "Platform", "Size", "Developer", "Category", "UpdateTime"},
+		func(a model.Asset) []string {
+			return []string{a.Name, a.Package, a.Version, a.Platform, a.Size, a.Developer, a.Category, a.UpdatedAt}
+		}},
+	{"domain", []string{"Domain", "Registrar", "RegisterTime", "ExpireTime", "Status", "Company", "UpdateTime"},
+		func(a model.Asset) []string {
+			return []string{a.Domain, a.Registrar, a.RegisterTime, a.ExpireTime, a.Status, a.ICPOrg, a.UpdatedAt}
+		}},
+	{"email", []string{"Email", "Source", "Company", "UpdateTime"},
+		func(a model.Asset) []string {
+			return []string{a.Email, a.Source, a.ICPOrg, a.UpdatedAt}
+		}},
+	{"code", []string{"Title", "URL", "Language", "Source", "UpdateTime"},
+		func(a model.Asset) []string {
+			return []string{a.Title, a.URL, a.Language, a.Source, a.UpdatedAt}
+		}},
+	{"member", []string{"Name", "Position", "Department", "Company", "Source", "UpdateTime"},
+		func(a model.Asset) []string {
+			return []string{a.Name, a.Position, a.Department, a.ICPOrg, a.Source, a.UpdatedAt}
+		}},
+}
+
+// typeRow 返回资产在对应类型工作表中的一行，无权限的类型只有一条提示记录，只显示标题和说明
+func typeRow(asset model.Asset, row func(model.Asset) []string) []string {
+	if asset.Title == "API访问受限" {
+		return []string{asset.Title, asset.Service}
+	}
+	return row(asset)
+}
+
+// otherSheet 未列出的数据类型使用的通用列
+var otherSheet = struct {
+	Headers []string
+	Row     func(model.Asset) []string
+}{
+	[]string{"Type", "IP", "Domain", "Port", "Service", "Title", "Company", "Source", "UpdateTime"},
+	func(a model.Asset) []string {
+		return []string{a.Type, a.IP, a.Domain, a.Port, a.Service, a.Title, a.ICPOrg, a.Source, a.UpdatedAt}
+	},
+}
+
+// typeSheetExporter 将公司情报结果按数据类型写入 xlsx 的不同工作表，每种类型使用各自的列
+type typeSheetExporter struct{}
+
+func (typeSheetExporter) Write(w io.Writer, assets []model.Asset) error {
+	byType := make(map[string][]model.Asset)
+	for _, asset := range assets {
+		byType[asset.Type] = append(byType[asset.Type], asset)
+	}
+
+	var sheets []excel.Sheet
+	for _, t := range typeSheets {
+		sheet := excel.Sheet{Name: strings.ToUpper(t.Type), Headers: t.Headers, EmptyMessage: emptySheetMessage}
+		for _, asset := range byType[t.Type] {
+			sheet.Rows = append(sheet.Rows, typeRow(asset, t.Row))
+		}
+		delete(byType, t.Type)
+		sheets = append(sheets, sheet)
+	}
+
+	// 其他引擎返回的类型不在上面的列表中时，统一写入 OTHER 工作表，避免丢失结果
+	if len(byType) > 0 {
+		sheet := excel.Sheet{Name: "OTHER", Headers: otherSheet.Headers}
+		for _, asset := range assets {
+			if _, ok := byType[asset.Type]; ok {
+				sheet.Rows = append(sheet.Rows, otherSheet.Row(asset))
+			}
+		}
+		sheets = append(sheets, sheet)
+	}
+	return excel.WriteSheets(w, sheets)
+}
+
+// Outputs 将 xlsx 输出替换为按数据类型分工作表的格式，其他格式仍使用通用的列
+func Outputs(outputs []export.Output) []export.Output {
+	result := make([]export.Output, len(outputs))
+	for i, o := range outputs {
+		if o.Format.Name == "xlsx" {
+			o.Format.Stream = nil
+			o.Format.Exporter = typeSheetExporter{}
+		}
+		result[i] = o
+	}
+	return result
+}
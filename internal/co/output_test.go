@@ -0,0 +1,89 @@
+package co
+
+import (
+	"cscan/internal/common/export"
+	"cscan/internal/common/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestOutputsWritesTypeSheets(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "company_assets")
+	outputs, err := export.ParseOutputs(base, "xlsx,csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []model.Asset{
+		{Type: "site", IP: "93.184.216.34", Domain: "www.example.com", Port: "443", ICPOrg: "示例科技有限公司", Source: "0.zone"},
+		// 与 site 中的域名相同，但属于不同类型，不应被去重
+		{Type: "domain", Domain: "www.example.com", Registrar: "Example Registrar", ExpireTime: "2030-01-01", Source: "0.zone"},
+		{Type: "apk", Name: "示例", Package: "com.example.app", Version: "2.1.0", Source: "0.zone"},
+		{Type: "member", Name: "张三", Position: "工程师", Department: "研发部", Source: "0.zone"},
+		{Type: "member", Name: "李四", Position: "经理", Department: "市场部", Source: "0.zone"},
+		{Type: "code", Title: "API访问受限", Service: "当前API Key无code数据访问权限", Source: "0.zone"},
+	}
+	if err := export.Save(results, Outputs(outputs), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(base + ".xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want := []string{"SITE", "APK", "DOMAIN", "EMAIL", "CODE", "MEMBER"}
+	if got := f.GetSheetList(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sheets = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		sheet string
+		rows  [][]string
+	}{
+		{"DOMAIN", [][]string{
+			{"Domain", "Registrar", "RegisterTime", "ExpireTime", "Status", "Company", "UpdateTime"},
+			{"www.example.com", "Example Registrar", "", "2030-01-01"},
+		}},
+		{"APK", [][]string{
+			{"Name", "Package", "Version", "Platform", "Size", "Developer", "Category", "UpdateTime"},
+			{"示例", "com.example.app", "2.1.0"},
+		}},
+		{"EMAIL", [][]string{
+			{"Email", "Source", "Company", "UpdateTime"},
+			{emptySheetMessage},
+		}},
+		{"CODE", [][]string{
+			{"Title", "URL", "Language", "Source", "UpdateTime"},
+			{"API访问受限", "当前API Key无code数据访问权限"},
+		}},
+		{"MEMBER", [][]string{
+			{"Name", "Position", "Department", "Company", "Source", "UpdateTime"},
+			{"张三", "工程师", "研发部", "", "0.zone"},
+			{"李四", "经理", "市场部", "", "0.zone"},
+		}},
+	}
+	for _, tt := range tests {
+		rows, err := f.GetRows(tt.sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("%s rows = %v, want %v", tt.sheet, rows, tt.rows)
+		}
+	}
+
+	// 其他格式仍使用通用的列
+	data, err := os.ReadFile(base + ".csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(strings.TrimPrefix(string(data), "\ufeff"), "IP,域名,端口") {
+		t.Errorf("csv = %q", data)
+	}
+}
@@ -0,0 +1,57 @@
+package co
+
+import (
+	"context"
+	"cscan/internal/common/config"
+	"cscan/internal/common/logger"
+	"cscan/internal/common/model"
+	"cscan/internal/cse"
+)
+
+// QueryScanner 支持直接执行原生查询语句的扫描器
+type QueryScanner interface {
+	Scanner
+
+	// SearchQuery 执行原生查询语句并返回资产列表
+	SearchQuery(ctx context.Context, query string, page, size int) ([]model.Asset, error)
+}
+
+// SearchQL 将统一查询语言编译为各引擎的查询语句并执行，合并所有引擎的结果
+// 不支持该查询的引擎会输出原因并跳过
+func SearchQL(ctx context.Context, input string, engines []Engine, cfg *config.Config, pageSize int) ([]model.Asset, error) {
+	node, err := cse.ParseQL(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []model.Asset
+	for _, engine := range engines {
+		if engine.Dialect == nil {
+			logger.Printf("[%s] 不支持统一查询语言，已跳过\n", engine.Name)
+			continue
+		}
+		query, err := engine.Dialect.Compile(node)
+		if err != nil {
+			logger.Printf("[%s] 查询语句%v，已跳过\n", engine.Name, err)
+			continue
+		}
+
+		for _, scanner := range engine.New(cfg) {
+			qs, ok := scanner.(QueryScanner)
+			if !ok {
+				logger.Printf("[%s] 不支持直接查询，已跳过\n", scanner.Name())
+				continue
+			}
+			logger.Printf("[%s] 搜索: %s\n", scanner.Name(), query)
+			assets, err := qs.SearchQuery(ctx, query, 1, pageSize)
+			results = append(results, assets...)
+			if err != nil {
+				if ctx.Err() != nil {
+					return results, ctx.Err()
+				}
+				logger.Printf("[%s] 查询出错: %v\n", scanner.Name(), err)
+			}
+		}
+	}
+	return results, ctx.Err()
+}
@@ -0,0 +1,129 @@
+package co
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Engine 可注册的公司情报引擎
+type Engine struct {
+	// Name 子模块名称，如 "zone"，用于 -m co <name> 选择引擎
+	Name string
+
+	// Description 帮助信息中的说明
+	Description string
+
+	// ConfigKeys 引擎需要的配置项，配置缺失时用于提示
+	ConfigKeys []string
+
+	// Configured 判断配置中是否提供了引擎需要的 Key
+	Configured func(cfg *config.Config) bool
+
+	// New 根据配置创建扫描器
+	New func(cfg *config.Config) []Scanner
+
+	// Dialect 统一查询语言到引擎查询语法的转换规则，为空表示不支持统一查询语言
+	Dialect *cse.Dialect
+}
+
+var (
+	registryMu sync.RWMutex
+	engines    = make(map[string]Engine)
+)
+
+// Register 注册公司情报引擎，通常在引擎包的 init 中调用
+// 名称重复或缺少构造函数时 panic
+func Register(engine Engine) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := strings.ToLower(engine.Name)
+	if name == "" || engine.New == nil {
+		panic("co: Register 缺少引擎名称或构造函数")
+	}
+	if _, dup := engines[name]; dup {
+		panic("co: 重复注册引擎 " + name)
+	}
+	engine.Name = name
+	engines[name] = engine
+}
+
+// Engines 返回所有已注册的引擎，按名称排序
+func Engines() []Engine {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]Engine, 0, len(engines))
+	for _, engine := range engines {
+		list = append(list, engine)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// EngineNames 返回所有已注册引擎的名称
+func EngineNames() []string {
+	var names []string
+	for _, engine := range Engines() {
+		names = append(names, engine.Name)
+	}
+	return names
+}
+
+// LookupEngine 按名称查找引擎，不区分大小写
+func LookupEngine(name string) (Engine, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	engine, ok := engines[strings.ToLower(strings.TrimSpace(name))]
+	return engine, ok
+}
+
+// SelectEngines 解析逗号分隔的引擎列表，列表为空时返回所有引擎
+func SelectEngines(list string) ([]Engine, error) {
+	if strings.TrimSpace(list) == "" {
+		return Engines(), nil
+	}
+
+	var selected []Engine
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		engine, ok := LookupEngine(name)
+		if !ok {
+			return nil, fmt.Errorf("未知的子模块: %s (可用子模块: %s)", name, strings.Join(EngineNames(), ", "))
+		}
+		if seen[engine.Name] {
+			continue
+		}
+		seen[engine.Name] = true
+		selected = append(selected, engine)
+	}
+	return selected, nil
+}
+
+// ValidateEngines 检查所选引擎是否都已配置
+func ValidateEngines(selected []Engine, cfg *config.Config) error {
+	for _, engine := range selected {
+		if engine.Configured != nil && !engine.Configured(cfg) {
+			return fmt.Errorf("引擎 %s 未配置，请在配置文件中设置 %s", engine.Name, strings.Join(engine.ConfigKeys, ", "))
+		}
+	}
+	return nil
+}
+
+// NewScanners 创建所选引擎的全部扫描器
+func NewScanners(selected []Engine, cfg *config.Config) []Scanner {
+	var scanners []Scanner
+	for _, engine := range selected {
+		scanners = append(scanners, engine.New(cfg)...)
+	}
+	return scanners
+}
@@ -0,0 +1,80 @@
+package zone
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"cscan/internal/common/model"
+	"cscan/internal/cse"
+)
+
+// Pool 多个 0.zone API Key 组成的账号池，当前 Key 额度耗尽或触发速率限制时切换到下一个
+// 0.zone 按数据类型授权，某类数据无权限不代表 Key 失效，此时不切换账号
+type Pool struct {
+	pool *cse.KeyPool
+}
+
+// keyScanner 账号池中的单个 Key，实现 cse.Scanner 以复用 cse.KeyPool 的切换逻辑
+type keyScanner struct {
+	*Scanner
+}
+
+// Search 在信息系统（site）数据中执行查询语句
+func (k keyScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	return k.SearchQuery(ctx, query, page, size)
+}
+
+// NewPool 使用多个 API Key 创建账号池
+func NewPool(keys []string, opts ...httpopt.Option) *Pool {
+	var members []cse.PoolMember
+	for _, key := range keys {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(key), Scanner: keyScanner{NewScanner(key, opts...)}})
+	}
+	return &Pool{pool: cse.NewKeyPool(members...)}
+}
+
+func (p *Pool) Name() string {
+	return "Zone"
+}
+
+// SearchByCompany 搜索公司的各类数据，每个请求使用当前可用的 Key
+func (p *Pool) SearchByCompany(ctx context.Context, company string, page, size int) (map[string][]model.Asset, error) {
+	return searchByCompany(ctx, company, page, size, func(ctx context.Context, company, queryType string, page, size int) ([]model.Asset, error) {
+		var (
+			assets  []model.Asset
+			typeErr error
+		)
+		err := p.pool.Do(ctx, func(s cse.Scanner) (int, error) {
+			assets, typeErr = s.(keyScanner).searchByType(ctx, company, queryType, page, size)
+			// 该类数据无权限时不切换账号，错误交给 searchByCompany 标记
+			if cerrors.KindOf(typeErr) == cerrors.KindAuthFailed {
+				return 0, nil
+			}
+			return len(assets), typeErr
+		})
+		if err != nil {
+			return nil, err
+		}
+		return assets, typeErr
+	})
+}
+
+// SearchQuery 在信息系统（site）数据中执行查询语句
+func (p *Pool) SearchQuery(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	return p.pool.Search(ctx, query, page, size)
+}
+
+// Quota 汇总所有 Key 的剩余额度
+func (p *Pool) Quota(ctx context.Context) (*model.Quota, error) {
+	return p.pool.Quota(ctx)
+}
+
+// RequestsPerCompany 返回搜索每个公司需要发出的请求数
+func (p *Pool) RequestsPerCompany() int {
+	return len(searchTypes)
+}
+
+// KeyUsage 返回各 Key 的使用情况
+func (p *Pool) KeyUsage() []cse.KeyUsage {
+	return p.pool.KeyUsage()
+}
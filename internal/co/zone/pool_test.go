@@ -0,0 +1,49 @@
+package zone
+
+import (
+	"context"
+	"cscan/internal/common/httpopt"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPoolRotatesOnExhaustedKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case body["zone_key_id"] == "exhausted-key":
+			w.Write([]byte(`{"code": 1, "message": "今日查询次数已用完"}`))
+		case r.URL.Path == "/api/data/member":
+			w.Write([]byte(`{"code": 1, "message": "无权限访问该数据"}`))
+		case r.URL.Path == "/api/data/site":
+			w.Write([]byte(`{"code": 0, "total": "1", "data": [{"ip": "1.1.1.1", "port": "443"}]}`))
+		default:
+			w.Write([]byte(`{"code": 0, "total": "0", "data": []}`))
+		}
+	}))
+	defer server.Close()
+
+	pool := NewPool([]string{"exhausted-key", "working-key"}, httpopt.WithBaseURL(server.URL))
+	results, err := pool.SearchByCompany(context.Background(), "示例科技", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results["site"]; len(got) != 1 || got[0].IP != "1.1.1.1" {
+		t.Errorf("site = %+v", got)
+	}
+	// 某类数据无权限不会停用 Key
+	if got := results["member"]; len(got) != 1 || got[0].Title != "API访问受限" {
+		t.Errorf("member = %+v", got)
+	}
+
+	usage := pool.KeyUsage()
+	if !usage[0].Exhausted || usage[0].Requests != 1 {
+		t.Errorf("exhausted key usage = %+v, want exhausted after 1 request", usage[0])
+	}
+	if usage[1].Exhausted || usage[1].Requests != len(searchTypes) || usage[1].Results != 1 {
+		t.Errorf("working key usage = %+v", usage[1])
+	}
+}
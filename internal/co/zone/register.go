@@ -0,0 +1,60 @@
+package zone
+
+import (
+	"cscan/internal/co"
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+)
+
+func init() {
+	co.Register(co.Engine{
+		Name:        "zone",
+		Description: "Zone 引擎",
+		ConfigKeys:  []string{"zone_api_key", "zone_api_keys"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.ZoneKeys()) > 0
+		},
+		New: func(cfg *config.Config) []co.Scanner {
+			return []co.Scanner{newFromConfig(cfg)}
+		},
+		Dialect: dialect,
+	})
+}
+
+// dialect 0.zone 信息系统（site）查询语法
+var dialect = &cse.Dialect{
+	Fields: map[string]string{
+		"ip":       "ip",
+		"port":     "port",
+		"domain":   "domain",
+		"host":     "url",
+		"title":    "title",
+		"body":     "html_banner",
+		"header":   "banner",
+		"app":      "component",
+		"protocol": "service",
+		"cert":     "ssl_info.detail",
+		"org":      "company",
+		"os":       "os",
+		"country":  "country",
+		"province": "province",
+		"city":     "city",
+	},
+	Match:    "==",
+	NotMatch: "!=",
+	And:      " && ",
+	Or:       " || ",
+}
+
+// newFromConfig 根据配置创建扫描器，配置了多个 Key 时返回账号池
+func newFromConfig(cfg *config.Config) co.Scanner {
+	opts := cfg.HTTPOptions("zone")
+	keys := cfg.ZoneKeys()
+	switch len(keys) {
+	case 0:
+		return NewScanner(cfg.ZoneAPIKey, opts...)
+	case 1:
+		return NewScanner(keys[0], opts...)
+	}
+	return NewPool(keys, opts...)
+}
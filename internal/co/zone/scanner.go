@@ -2,6 +2,10 @@ package zone
 
 import (
 	"bytes"
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"cscan/internal/common/logger"
 	"cscan/internal/common/model"
 	"encoding/json"
 	"fmt"
@@ -11,13 +15,14 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/xuri/excelize/v2"
 )
 
+// DefaultBaseURL 0.zone API 默认地址
+const DefaultBaseURL = "https://0.zone"
+
 type Scanner struct {
-	client *http.Client
-	key    string
+	httpopt.Client
+	key string
 }
 
 type ZoneAsset struct {
@@ -31,9 +36,9 @@ type ZoneAsset struct {
 	Company  string `json:"company"`
 }
 
-func NewScanner(apiKey string) *Scanner {
+func NewScanner(apiKey string, opts ...httpopt.Option) *Scanner {
 	return &Scanner{
-		client: &http.Client{},
+		Client: httpopt.New(DefaultBaseURL, opts...),
 		key:    apiKey,
 	}
 }
@@ -42,43 +47,31 @@ func (s *Scanner) Name() string {
 	return "Zone"
 }
 
-// 定义支持的搜索类型和对应的表头
-var searchTypes = map[string][]string{
-	"site": {
-		"IP", "Domain", "Port", "Service", "Title", "Status",
-		"Location", "Company", "UpdateTime",
-	},
-	"apk": {
-		"Name", "Package", "Version", "Platform", "Size",
-		"Developer", "Category", "UpdateTime",
-	},
-	"domain": {
-		"Domain", "Registrar", "RegisterTime", "ExpireTime",
-		"Status", "Company", "UpdateTime",
-	},
-	"email": {
-		"Email", "Source", "Company", "UpdateTime",
-	},
-	"code": {
-		"Title", "URL", "Language", "Source", "UpdateTime",
-	},
-	"member": {
-		"Name", "Position", "Department", "Company", "Source", "UpdateTime",
-	},
+// searchTypes 搜索公司时查询的数据类型，结果按类型合并后统一输出
+var searchTypes = []string{"site", "apk", "domain", "email", "code", "member"}
+
+func (s *Scanner) SearchByCompany(ctx context.Context, company string, page, size int) (map[string][]model.Asset, error) {
+	return searchByCompany(ctx, company, page, size, s.searchByType)
 }
 
-func (s *Scanner) SearchByCompany(company string, page, size int) (map[string][]model.Asset, error) {
+// searchByCompany 使用 searchByType 依次查询公司的各类数据，单个类型失败不影响其他类型
+func searchByCompany(ctx context.Context, company string, page, size int,
+	searchByType func(ctx context.Context, company, queryType string, page, size int) ([]model.Asset, error)) (map[string][]model.Asset, error) {
 	results := make(map[string][]model.Asset)
-	fmt.Printf("正在搜索公司: %s\n", company)
+	logger.Printf("正在搜索公司: %s\n", company)
 
 	// 遍历所有搜索类型
-	for searchType := range searchTypes {
-		typeResults, err := s.searchByType(company, searchType, page, size)
+	for _, searchType := range searchTypes {
+		typeResults, err := searchByType(ctx, company, searchType, page, size)
 		if err != nil {
-			fmt.Printf("- %s搜索失败: %v\n", searchType, err)
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			logger.Printf("- %s搜索失败: %v\n", searchType, err)
 			// 如果是权限错误，添加一个特殊的资产来标记
-			if strings.Contains(err.Error(), "无权限") || strings.Contains(err.Error(), "未授权") {
+			if cerrors.KindOf(err) == cerrors.KindAuthFailed {
 				results[searchType] = []model.Asset{{
+					Type:    searchType,
 					Title:   "API访问受限",
 					Source:  "0.zone",
 					Service: fmt.Sprintf("当前API Key无%s数据访问权限", searchType),
@@ -87,7 +80,7 @@ func (s *Scanner) SearchByCompany(company string, page, size int) (map[string][]
 			continue
 		}
 		if len(typeResults) > 0 {
-			fmt.Printf("- 找到%d个%s资产\n", len(typeResults), searchType)
+			logger.Printf("- 找到%d个%s资产\n", len(typeResults), searchType)
 			results[searchType] = typeResults
 		}
 	}
@@ -95,8 +88,7 @@ func (s *Scanner) SearchByCompany(company string, page, size int) (map[string][]
 	return results, nil
 }
 
-func (s *Scanner) searchByType(company, queryType string, page, size int) ([]model.Asset, error) {
-	baseURL := "https://0.zone/api/data/" + queryType
+func (s *Scanner) searchByType(ctx context.Context, company, queryType string, page, size int) ([]model.Asset, error) {
 	requestBody := map[string]interface{}{
 		"query":       buildQuery(company),
 		"query_type":  queryType,
@@ -110,21 +102,21 @@ func (s *Scanner) searchByType(company, queryType string, page, size int) ([]mod
 		return nil, fmt.Errorf("构建请求体失败: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", baseURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/api/data/"+queryType, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), fmt.Errorf("请求失败: %v", err))
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), fmt.Errorf("读取响应失败: %v", err))
 	}
 
 	// 解析响应
@@ -158,20 +150,41 @@ func (s *Scanner) searchByType(company, queryType string, page, size int) ([]mod
 			Platform  string      `json:"platform"`
 			Name      string      `json:"name"`
 			Component string      `json:"component"`
+			// 各数据类型特有的字段
+			Registrar    string      `json:"registrar"`
+			RegisterTime string      `json:"register_time"`
+			ExpireTime   string      `json:"expire_time"`
+			Package      string      `json:"package"`
+			Version      string      `json:"version"`
+			Size         interface{} `json:"size"` // 可能是字符串或数字
+			Developer    string      `json:"developer"`
+			Category     string      `json:"category"`
+			Email        string      `json:"email"`
+			Language     string      `json:"language"`
+			Department   string      `json:"department"`
+			Position     string      `json:"position"`
+			Timestamp    string      `json:"timestamp"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), fmt.Errorf("解析响应失败: %v", err))
 	}
 
 	if response.Code != 0 {
-		return nil, fmt.Errorf("%s", response.Message)
+		return nil, cerrors.FromAPI(s.Name(), resp, response.Message)
 	}
 
 	var results []model.Asset
 	for _, item := range response.Data {
-		asset := model.Asset{Source: "0.zone"}
+		asset := model.Asset{
+			Type:      queryType,
+			Source:    "0.zone",
+			UpdatedAt: item.Timestamp,
+		}
 
 		switch queryType {
 		case "site":
@@ -190,37 +203,42 @@ func (s *Scanner) searchByType(company, queryType string, page, size int) ([]mod
 					asset.Domain = u.Hostname()
 				}
 			}
+			asset.StatusCode = firstString(item.Status)
+			asset.ICPOrg = firstString(item.Company)
 			asset.Location = formatLocation(item.Country, item.Province, item.City)
 
 		case "domain", "org":
-			// 处理 domain 字段
-			if item.Domain != nil {
-				switch d := item.Domain.(type) {
-				case string:
-					asset.Domain = d
-				case []interface{}:
-					if len(d) > 0 {
-						if str, ok := d[0].(string); ok {
-							asset.Domain = str
-						}
-					}
-				}
-			}
-
-			// 处理 company 字段
-			if item.Company != nil {
-				switch c := item.Company.(type) {
-				case string:
-					asset.ICPOrg = c
-				case []interface{}:
-					if len(c) > 0 {
-						if str, ok := c[0].(string); ok {
-							asset.ICPOrg = str
-						}
-					}
-				}
-			}
+			asset.Domain = firstString(item.Domain)
+			asset.ICPOrg = firstString(item.Company)
+			asset.Registrar = item.Registrar
+			asset.RegisterTime = item.RegisterTime
+			asset.ExpireTime = item.ExpireTime
+			asset.Status = firstString(item.Status)
 			asset.Location = formatLocation(item.Country, item.Province, item.City)
+
+		case "apk":
+			asset.Name = item.Name
+			asset.Package = item.Package
+			asset.Version = item.Version
+			asset.Platform = item.Platform
+			asset.Size = firstString(item.Size)
+			asset.Developer = item.Developer
+			asset.Category = item.Category
+
+		case "email":
+			asset.Email = item.Email
+			asset.ICPOrg = firstString(item.Company)
+
+		case "code":
+			asset.Title = item.Title
+			asset.URL = item.URL
+			asset.Language = item.Language
+
+		case "member":
+			asset.Name = item.Name
+			asset.Position = item.Position
+			asset.Department = item.Department
+			asset.ICPOrg = firstString(item.Company)
 		}
 
 		if isValidAsset(asset, queryType) {
@@ -231,6 +249,58 @@ func (s *Scanner) searchByType(company, queryType string, page, size int) ([]mod
 	return results, nil
 }
 
+// RequestsPerCompany 返回搜索每个公司需要发出的请求数
+func (s *Scanner) RequestsPerCompany() int {
+	return len(searchTypes)
+}
+
+// Quota 查询 API Key 的剩余额度
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"zone_key_id": s.key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("构建请求体失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/api/data/key_info", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), fmt.Errorf("请求失败: %v", err))
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Remaining json.Number `json:"surplus_num"`
+			Total     json.Number `json:"total_num"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), fmt.Errorf("解析响应失败: %v", err))
+	}
+	if response.Code != 0 {
+		return nil, cerrors.FromAPI(s.Name(), resp, response.Message)
+	}
+
+	remaining, _ := response.Data.Remaining.Int64()
+	return &model.Quota{
+		Remaining: int(remaining),
+		Unit:      "次",
+		Detail:    fmt.Sprintf("总额度 %s 次", response.Data.Total),
+	}, nil
+}
+
 // formatLocation 格式化位置信息
 func formatLocation(country, province, city string) string {
 	var parts []string
@@ -242,6 +312,25 @@ func formatLocation(country, province, city string) string {
 	return strings.Join(parts, "/")
 }
 
+// firstString 将可能是字符串、数字或数组的字段转换为字符串，数组取第一个元素
+func firstString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		if len(value) > 0 {
+			return firstString(value[0])
+		}
+		return ""
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
 // isValidAsset 检查资产是否有效
 func isValidAsset(asset model.Asset, queryType string) bool {
 	switch queryType {
@@ -251,122 +340,21 @@ func isValidAsset(asset model.Asset, queryType string) bool {
 		return asset.Domain != ""
 	case "org":
 		return asset.ICPOrg != ""
+	case "apk":
+		return asset.Name != "" || asset.Package != ""
+	case "member":
+		return asset.Name != ""
+	case "email":
+		return asset.Email != ""
+	case "code":
+		return asset.URL != "" || asset.Title != ""
 	default:
 		return true
 	}
 }
 
-// SaveResults 将结果保存到Excel文件的不同sheet中
-func (s *Scanner) SaveResults(results map[string][]model.Asset, filename string) error {
-	f := excelize.NewFile()
-
-	// 删除默认的Sheet1
-	f.DeleteSheet("Sheet1")
-
-	// 确保所有支持的类型都有对应的sheet
-	for searchType := range searchTypes {
-		// 创建新的sheet
-		sheetName := strings.ToUpper(searchType)
-		_, err := f.NewSheet(sheetName)
-		if err != nil {
-			return fmt.Errorf("创建sheet失败: %v", err)
-		}
-
-		// 写入表头
-		headers := searchTypes[searchType]
-		for i, header := range headers {
-			cell := fmt.Sprintf("%c1", 'A'+i)
-			f.SetCellValue(sheetName, cell, header)
-		}
-
-		// 获取当前类型的资产
-		assets := results[searchType]
-
-		// 如果没有数据，添加说明行
-		if len(assets) == 0 {
-			cell := "A2"
-			message := "当前API Key无此类型数据访问权限或未找到相关数据"
-			f.SetCellValue(sheetName, cell, message)
-			f.MergeCell(sheetName, cell, fmt.Sprintf("%c2", 'A'+len(headers)-1))
-		} else {
-			// 写入数据
-			for i, asset := range assets {
-				row := i + 2
-				data := s.formatAssetData(asset, searchType)
-				for j, value := range data {
-					cell := fmt.Sprintf("%c%d", 'A'+j, row)
-					f.SetCellValue(sheetName, cell, value)
-				}
-			}
-		}
-
-		// 设置自动筛选
-		lastCol := string('A' + len(headers) - 1)
-		lastRow := max(len(assets)+1, 2)
-		f.AutoFilter(sheetName, fmt.Sprintf("A1:%s%d", lastCol, lastRow), nil)
-
-		// 冻结首行
-		f.SetPanes(sheetName, &excelize.Panes{
-			Freeze:      true,
-			Split:       false,
-			XSplit:      0,
-			YSplit:      1,
-			TopLeftCell: "A2",
-			ActivePane:  "bottomLeft",
-		})
-
-		// 调整列宽
-		for i := range headers {
-			col := string('A' + i)
-			f.SetColWidth(sheetName, col, col, 20)
-		}
-	}
-
-	// 保存文件
-	return f.SaveAs(filename)
-}
-
-// max 返回两个整数中的较大值
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-// formatAssetData 根据不同类型格式化资产数据
-func (s *Scanner) formatAssetData(asset model.Asset, searchType string) []string {
-	switch searchType {
-	case "site":
-		return []string{
-			asset.IP,
-			asset.Domain,
-			asset.Port,
-			asset.Service,
-			asset.Title,
-			asset.StatusCode,
-			asset.Location,
-			asset.ICPOrg,
-			asset.UpdatedAt,
-		}
-	case "domain":
-		return []string{
-			asset.Domain,
-			asset.Registrar,
-			asset.RegisterTime,
-			asset.ExpireTime,
-			asset.Status,
-			asset.ICPOrg,
-			asset.UpdatedAt,
-		}
-	// ... 其他类型的格式化逻辑
-	default:
-		return []string{asset.Title, asset.Service}
-	}
-}
-
 // Search 执行搜索
-func (s *Scanner) Search(query string, queryType string, page, size int) ([]model.Asset, error) {
+func (s *Scanner) Search(ctx context.Context, query string, queryType string, page, size int) ([]model.Asset, error) {
 	var allAssets []model.Asset
 	currentPage := page
 
@@ -390,19 +378,18 @@ func (s *Scanner) Search(query string, queryType string, page, size int) ([]mode
 		}
 
 		// 创建请求
-		req, err := http.NewRequest("POST", "https://0.zone/api/data/", bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/api/data/", bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, fmt.Errorf("创建请求失败: %v", err)
 		}
 
 		// 设置请求头
-		req.Header.Set("Host", "0.zone")
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Content-Length", fmt.Sprintf("%d", len(jsonData)))
 
-		resp, err := s.client.Do(req)
+		resp, err := s.HTTPClient.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("请求失败: %v", err)
+			return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), fmt.Errorf("请求失败: %v", err))
 		}
 		defer resp.Body.Close()
 
@@ -430,11 +417,14 @@ func (s *Scanner) Search(query string, queryType string, page, size int) ([]mode
 		}
 
 		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-			return nil, fmt.Errorf("解析响应失败: %v", err)
+			if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+				return nil, se
+			}
+			return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), fmt.Errorf("解析响应失败: %v", err))
 		}
 
 		if response.Code != 0 {
-			return nil, fmt.Errorf("API错误: %s", response.Message)
+			return nil, cerrors.FromAPI(s.Name(), resp, response.Message)
 		}
 
 		// 处理总页数和总记录数
@@ -444,7 +434,7 @@ func (s *Scanner) Search(query string, queryType string, page, size int) ([]mode
 		}
 		totalPages := (total + size - 1) / size
 
-		fmt.Printf("正在获取第 %d/%d 页数据，总记录数: %d (API限制最多返回%d条)\n",
+		logger.Printf("正在获取第 %d/%d 页数据，总记录数: %d (API限制最多返回%d条)\n",
 			currentPage, totalPages, total, maxResults)
 
 		// 转换结果
@@ -485,19 +475,28 @@ func (s *Scanner) Search(query string, queryType string, page, size int) ([]mode
 		currentPage++
 
 		// 添加延时，避免请求过快
-		time.Sleep(time.Second)
+		select {
+		case <-ctx.Done():
+			return allAssets, ctx.Err()
+		case <-time.After(time.Second):
+		}
 
 		// 检查是否达到API限制
 		if len(allAssets) >= maxResults {
-			fmt.Printf("已达到API返回上限(%d条记录)\n", maxResults)
+			logger.Printf("已达到API返回上限(%d条记录)\n", maxResults)
 			break
 		}
 	}
 
-	fmt.Printf("共获取到 %d 条记录\n", len(allAssets))
+	logger.Printf("共获取到 %d 条记录\n", len(allAssets))
 	return allAssets, nil
 }
 
+// SearchQuery 在信息系统（site）数据中执行查询语句
+func (s *Scanner) SearchQuery(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	return s.Search(ctx, query, "site", page, size)
+}
+
 // buildQuery 构建查询语句
 func buildQuery(company string) string {
 	// 构建完整的查询条件，确保每个值都用引号包围
@@ -513,18 +512,18 @@ func buildQuery(company string) string {
 }
 
 // SearchCompany 搜索公司信息
-func (s *Scanner) SearchCompany(company string, maxPage, pageSize int) ([]model.Asset, error) {
-	fmt.Printf("正在搜索公司: %s\n", company)
+func (s *Scanner) SearchCompany(ctx context.Context, company string, maxPage, pageSize int) ([]model.Asset, error) {
+	logger.Printf("正在搜索公司: %s\n", company)
 
 	var allAssets []model.Asset
 
 	// 构建查询语句
 	query := buildQuery(company)
-	assets, err := s.Search(query, "site", 1, pageSize)
+	assets, err := s.Search(ctx, query, "site", 1, pageSize)
 	if err != nil {
-		fmt.Printf("- site搜索失败: %v\n", err)
+		logger.Printf("- site搜索失败: %v\n", err)
 	} else {
-		fmt.Printf("- 找到%d个site资产\n", len(assets))
+		logger.Printf("- 找到%d个site资产\n", len(assets))
 		allAssets = append(allAssets, assets...)
 	}
 
@@ -0,0 +1,116 @@
+package zone
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器，responses 按请求路径返回固定响应，
+// 未列出的路径返回空结果
+func newTestScanner(t *testing.T, responses map[string]string) (*Scanner, map[string]map[string]interface{}) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests[r.URL.Path] = body
+		mu.Unlock()
+		if resp, ok := responses[r.URL.Path]; ok {
+			w.Write([]byte(resp))
+			return
+		}
+		w.Write([]byte(`{"code": 0, "total": "0", "data": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewScanner("secret", httpopt.WithBaseURL(server.URL)), requests
+}
+
+func TestSearchByCompany(t *testing.T) {
+	s, requests := newTestScanner(t, map[string]string{
+		"/api/data/site": `{"code": 0, "total": "1", "data": [{
+			"ip": "93.184.216.34", "port": "443", "service": "https", "component": "nginx",
+			"title": "示例科技", "url": "https://www.example.com/login",
+			"country": "中国", "province": "北京", "city": ""
+		}]}`,
+		"/api/data/domain": `{"code": 0, "total": "1", "data": [{
+			"domain": ["example.com", "example.cn"], "company": "示例科技有限公司", "country": "中国"
+		}]}`,
+		"/api/data/apk": `{"code": 0, "total": "1", "data": [{
+			"name": "示例", "package": "com.example.app", "version": "2.1.0", "platform": "android",
+			"size": 10485760, "developer": "示例科技有限公司", "timestamp": "2024-05-01"
+		}]}`,
+		"/api/data/member": `{"code": 1, "message": "无权限访问该数据"}`,
+	})
+
+	results, err := s.SearchByCompany(context.Background(), "示例科技", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != s.RequestsPerCompany() {
+		t.Errorf("sent %d requests, want %d", len(requests), s.RequestsPerCompany())
+	}
+	site := requests["/api/data/site"]
+	if site["zone_key_id"] != "secret" || site["query_type"] != "site" || !strings.Contains(site["query"].(string), `company=="示例科技"`) {
+		t.Errorf("site request = %v", site)
+	}
+
+	if got := results["site"]; len(got) != 1 || got[0].Type != "site" || got[0].Domain != "www.example.com" || got[0].Service != "https/nginx" ||
+		got[0].Location != "中国/北京" {
+		t.Errorf("site = %+v", got)
+	}
+	if got := results["domain"]; len(got) != 1 || got[0].Domain != "example.com" || got[0].ICPOrg != "示例科技有限公司" {
+		t.Errorf("domain = %+v", got)
+	}
+	if got := results["apk"]; len(got) != 1 || got[0].Type != "apk" || got[0].Package != "com.example.app" ||
+		got[0].Version != "2.1.0" || got[0].Size != "10485760" || got[0].UpdatedAt != "2024-05-01" {
+		t.Errorf("apk = %+v", got)
+	}
+	// 无权限的类型保留一条提示记录
+	if got := results["member"]; len(got) != 1 || got[0].Type != "member" || got[0].Title != "API访问受限" {
+		t.Errorf("member = %+v", got)
+	}
+}
+
+func TestSearchQueryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want cerrors.Kind
+	}{
+		{"次数已用完", `{"code": 1, "message": "今日查询次数已用完"}`, cerrors.KindQuotaExhausted},
+		{"无效的 Key", `{"code": 1, "message": "未授权的 key"}`, cerrors.KindAuthFailed},
+		{"无法解析的响应", `not json`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s, _ := newTestScanner(t, map[string]string{"/api/data/": tt.body})
+		_, err := s.SearchQuery(context.Background(), `title=="test"`, 1, 10)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s, requests := newTestScanner(t, map[string]string{
+		"/api/data/key_info": `{"code": 0, "data": {"surplus_num": 4500, "total_num": 5000}}`,
+	})
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests["/api/data/key_info"]["zone_key_id"] != "secret" {
+		t.Errorf("request = %v", requests["/api/data/key_info"])
+	}
+	if quota.Remaining != 4500 || quota.Detail != "总额度 5000 次" {
+		t.Errorf("quota = %+v", quota)
+	}
+}
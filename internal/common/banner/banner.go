@@ -1,6 +1,6 @@
 package banner
 
-import "fmt"
+import "cscan/internal/common/logger"
 
 const banner = `
     ___    ___                    
@@ -15,5 +15,5 @@ const banner = `
 
 // PrintBanner 打印程序 banner
 func PrintBanner() {
-	fmt.Println(banner)
+	logger.Print(banner + "\n")
 }
@@ -1,20 +1,80 @@
 package config
 
 import (
+	"cscan/internal/common/httpopt"
+	"cscan/internal/common/logger"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// Budget 单个引擎每次运行的额度上限，0 表示不限制
+type Budget struct {
+	MaxRequests int `json:"max_requests"`
+	MaxResults  int `json:"max_results"`
+}
+
+// RetryPolicy 单个引擎单页请求失败后的重试策略，0 表示使用默认值
+type RetryPolicy struct {
+	MaxAttempts int `json:"max_attempts"`       // 每页最多尝试次数（含首次请求）
+	BaseDelay   int `json:"base_delay_seconds"` // 第一次重试前的等待秒数，之后每次翻倍
+	MaxDelay    int `json:"max_delay_seconds"`  // 单次等待上限秒数
+}
+
+// Endpoint 单个引擎的 API 地址和代理，为空时使用默认值
+type Endpoint struct {
+	BaseURL string `json:"base_url,omitempty"` // 自定义 API 地址，如私有化部署或反向代理
+	Proxy   string `json:"proxy,omitempty"`    // 请求使用的代理，如 http://127.0.0.1:8080 或 socks5://127.0.0.1:1080
+}
+
+// FofaAccount FOFA 账号
+type FofaAccount struct {
+	Email string `json:"email"`
+	Key   string `json:"key"`
+}
+
+// CensysAccount Censys 账号
+type CensysAccount struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
 type Config struct {
 	HunterAPIKey string `json:"hunter_api_key"`
 	FofaEmail    string `json:"fofa_email"`
 	FofaAPIKey   string `json:"fofa_api_key"`
 	ZoneAPIKey   string `json:"zone_api_key"`
 	QuakeAPIKey  string `json:"quake_api_key"`
+	ShodanAPIKey string `json:"shodan_api_key"`
+	CensysAPIID  string `json:"censys_api_id"`
+	CensysSecret string `json:"censys_api_secret"`
+	ZoomEyeKey   string `json:"zoomeye_api_key"`
 	MaxPage      int    `json:"max_page"`
 	PageSize     int    `json:"page_size"`
+	CacheDir     string `json:"cache_dir"`
+	CacheTTL     int    `json:"cache_ttl_hours"` // 本地缓存有效期（小时），0 表示使用默认值
+	CacheMaxSize int    `json:"cache_max_mb"`    // 本地缓存目录的总大小上限（MB），0 表示使用默认值
+
+	// 多账号配置，与上面的单个 Key 合并使用，额度耗尽时自动切换
+	HunterAPIKeys  []string        `json:"hunter_api_keys,omitempty"`
+	FofaAccounts   []FofaAccount   `json:"fofa_accounts,omitempty"`
+	QuakeAPIKeys   []string        `json:"quake_api_keys,omitempty"`
+	ShodanAPIKeys  []string        `json:"shodan_api_keys,omitempty"`
+	CensysAccounts []CensysAccount `json:"censys_accounts,omitempty"`
+	ZoomEyeAPIKeys []string        `json:"zoomeye_api_keys,omitempty"`
+	ZoneAPIKeys    []string        `json:"zone_api_keys,omitempty"`
+
+	// Budgets 各引擎每次运行的额度上限，键为 cse 引擎名 (hunter/fofa/quake/shodan/censys/zoomeye)
+	Budgets map[string]Budget `json:"budgets,omitempty"`
+
+	// Retry 各引擎的重试策略，键为 cse 引擎名 (hunter/fofa/quake/shodan/censys/zoomeye)
+	Retry map[string]RetryPolicy `json:"retry,omitempty"`
+
+	// Endpoints 各引擎的 API 地址和代理，键为引擎名 (hunter/fofa/quake/shodan/censys/zoomeye/zone)
+	Endpoints map[string]Endpoint `json:"endpoints,omitempty"`
 }
 
 // 默认配置
@@ -24,8 +84,15 @@ var defaultConfig = Config{
 	FofaAPIKey:   "your-fofa-key",
 	ZoneAPIKey:   "your-zone-key",
 	QuakeAPIKey:  "your-quake-key",
+	ShodanAPIKey: "your-shodan-key",
+	CensysAPIID:  "your-censys-id",
+	CensysSecret: "your-censys-secret",
+	ZoomEyeKey:   "your-zoomeye-key",
 	MaxPage:      5,
 	PageSize:     100,
+	CacheDir:     ".cscan_cache",
+	CacheTTL:     24,
+	CacheMaxSize: 512,
 }
 
 // Load 加载配置文件，如果文件不存在则创建默认配置
@@ -42,8 +109,8 @@ func Load(path string) (*Config, error) {
 		if err := createDefaultConfig(path); err != nil {
 			return nil, fmt.Errorf("创建默认配置文件失败: %v", err)
 		}
-		fmt.Printf("已创建默认配置文件: %s\n", path)
-		fmt.Println("请修改配置文件中的API密钥后再运行程序")
+		logger.Printf("已创建默认配置文件: %s\n", path)
+		logger.Println("请修改配置文件中的API密钥后再运行程序")
 		os.Exit(0)
 	}
 
@@ -83,27 +150,139 @@ func validateConfig(cfg *Config) error {
 	if cfg.PageSize <= 0 {
 		return fmt.Errorf("page_size 必须大于 0")
 	}
+	for name, budget := range cfg.Budgets {
+		if budget.MaxRequests < 0 || budget.MaxResults < 0 {
+			return fmt.Errorf("budgets.%s 的额度上限不能为负数", name)
+		}
+	}
+	for name, policy := range cfg.Retry {
+		if policy.MaxAttempts < 0 || policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return fmt.Errorf("retry.%s 的配置不能为负数", name)
+		}
+	}
+
+	for name, endpoint := range cfg.Endpoints {
+		if endpoint.BaseURL != "" {
+			if u, err := url.Parse(endpoint.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("endpoints.%s.base_url 无效: %s", name, endpoint.BaseURL)
+			}
+		}
+		if endpoint.Proxy != "" {
+			if u, err := url.Parse(endpoint.Proxy); err != nil || u.Host == "" ||
+				(u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+				return fmt.Errorf("endpoints.%s.proxy 无效，支持 http、https 和 socks5 代理: %s", name, endpoint.Proxy)
+			}
+		}
+	}
 
-	// 检查是否使用了默认值
-	if cfg.HunterAPIKey == defaultConfig.HunterAPIKey ||
-		cfg.FofaEmail == defaultConfig.FofaEmail ||
-		cfg.FofaAPIKey == defaultConfig.FofaAPIKey ||
-		cfg.ZoneAPIKey == defaultConfig.ZoneAPIKey ||
-		cfg.QuakeAPIKey == defaultConfig.QuakeAPIKey {
+	// 检查是否至少配置了一个 API Key
+	if len(cfg.HunterKeys()) == 0 &&
+		len(cfg.FofaKeys()) == 0 &&
+		len(cfg.QuakeKeys()) == 0 &&
+		len(cfg.ShodanKeys()) == 0 &&
+		len(cfg.CensysKeys()) == 0 &&
+		len(cfg.ZoomEyeKeys()) == 0 &&
+		len(cfg.ZoneKeys()) == 0 {
 		return fmt.Errorf("请修改配置文件中的默认API密钥")
 	}
 
 	return nil
 }
 
+// HunterKeys 返回所有已配置的 Hunter API Key，忽略空值和默认占位值
+func (c *Config) HunterKeys() []string {
+	return mergeKeys(defaultConfig.HunterAPIKey, append([]string{c.HunterAPIKey}, c.HunterAPIKeys...))
+}
+
+// QuakeKeys 返回所有已配置的 Quake API Key，忽略空值和默认占位值
+func (c *Config) QuakeKeys() []string {
+	return mergeKeys(defaultConfig.QuakeAPIKey, append([]string{c.QuakeAPIKey}, c.QuakeAPIKeys...))
+}
+
+// FofaKeys 返回所有已配置的 FOFA 账号，忽略空值和默认占位值
+func (c *Config) FofaKeys() []FofaAccount {
+	var accounts []FofaAccount
+	seen := make(map[string]bool)
+	for _, account := range append([]FofaAccount{{Email: c.FofaEmail, Key: c.FofaAPIKey}}, c.FofaAccounts...) {
+		if account.Key == "" || account.Key == defaultConfig.FofaAPIKey || seen[account.Key] {
+			continue
+		}
+		seen[account.Key] = true
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// ShodanKeys 返回所有已配置的 Shodan API Key，忽略空值和默认占位值
+func (c *Config) ShodanKeys() []string {
+	return mergeKeys(defaultConfig.ShodanAPIKey, append([]string{c.ShodanAPIKey}, c.ShodanAPIKeys...))
+}
+
+// CensysKeys 返回所有已配置的 Censys 账号，忽略 ID 或 Secret 为空值和默认占位值的账号
+func (c *Config) CensysKeys() []CensysAccount {
+	var accounts []CensysAccount
+	seen := make(map[string]bool)
+	for _, account := range append([]CensysAccount{{ID: c.CensysAPIID, Secret: c.CensysSecret}}, c.CensysAccounts...) {
+		if account.ID == "" || account.ID == defaultConfig.CensysAPIID ||
+			account.Secret == "" || account.Secret == defaultConfig.CensysSecret || seen[account.ID] {
+			continue
+		}
+		seen[account.ID] = true
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// ZoomEyeKeys 返回所有已配置的 ZoomEye API Key，忽略空值和默认占位值
+func (c *Config) ZoomEyeKeys() []string {
+	return mergeKeys(defaultConfig.ZoomEyeKey, append([]string{c.ZoomEyeKey}, c.ZoomEyeAPIKeys...))
+}
+
+// ZoneKeys 返回所有已配置的 0.zone API Key，忽略空值和默认占位值
+func (c *Config) ZoneKeys() []string {
+	return mergeKeys(defaultConfig.ZoneAPIKey, append([]string{c.ZoneAPIKey}, c.ZoneAPIKeys...))
+}
+
+// HTTPOptions 返回配置中指定引擎的 API 地址和代理，name 为引擎名，不区分大小写
+func (c *Config) HTTPOptions(name string) []httpopt.Option {
+	var opts []httpopt.Option
+	for key, endpoint := range c.Endpoints {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		if endpoint.BaseURL != "" {
+			opts = append(opts, httpopt.WithBaseURL(endpoint.BaseURL))
+		}
+		// 代理地址已在加载配置时校验
+		if proxy, err := url.Parse(endpoint.Proxy); endpoint.Proxy != "" && err == nil {
+			opts = append(opts, httpopt.WithProxy(proxy))
+		}
+	}
+	return opts
+}
+
+// mergeKeys 去重并过滤空值和默认占位值
+func mergeKeys(placeholder string, keys []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if key == "" || key == placeholder || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, key)
+	}
+	return result
+}
+
 // LoadOrCreate 合并 Load 和配置文件创建逻辑
 func LoadOrCreate(path string) (*Config, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		if err := createDefaultConfig(path); err != nil {
 			return nil, fmt.Errorf("创建配置文件失败: %v", err)
 		}
-		fmt.Printf("已创建默认配置文件: %s\n", path)
-		fmt.Println("请修改配置文件中的API密钥后再运行程序")
+		logger.Printf("已创建默认配置文件: %s\n", path)
+		logger.Println("请修改配置文件中的API密钥后再运行程序")
 		os.Exit(0)
 	}
 
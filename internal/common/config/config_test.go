@@ -0,0 +1,103 @@
+package config
+
+import (
+	"cscan/internal/common/httpopt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMultipleKeys(t *testing.T) {
+	cfg := &Config{
+		ShodanAPIKey:  defaultConfig.ShodanAPIKey,
+		ShodanAPIKeys: []string{"shodan-1", "", "shodan-2", "shodan-1"},
+		CensysAPIID:   "id-1",
+		CensysSecret:  "secret-1",
+		CensysAccounts: []CensysAccount{
+			{ID: "id-2", Secret: defaultConfig.CensysSecret},
+			{ID: "id-3", Secret: "secret-3"},
+			{ID: "id-1", Secret: "secret-1"},
+		},
+		ZoomEyeKey: "zoomeye-1",
+		ZoneAPIKey: defaultConfig.ZoneAPIKey,
+	}
+
+	if got, want := cfg.ShodanKeys(), []string{"shodan-1", "shodan-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShodanKeys() = %v, want %v", got, want)
+	}
+	if got, want := cfg.CensysKeys(), []CensysAccount{{"id-1", "secret-1"}, {"id-3", "secret-3"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CensysKeys() = %v, want %v", got, want)
+	}
+	if got, want := cfg.ZoomEyeKeys(), []string{"zoomeye-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ZoomEyeKeys() = %v, want %v", got, want)
+	}
+	if got := cfg.ZoneKeys(); len(got) != 0 {
+		t.Errorf("ZoneKeys() = %v, want none", got)
+	}
+}
+
+func TestHTTPOptions(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 经过代理的请求使用完整的目标地址
+		proxied = r.URL.String()
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+
+	cfg := &Config{Endpoints: map[string]Endpoint{
+		"FOFA":   {BaseURL: "https://fofa.example.internal/"},
+		"shodan": {BaseURL: "http://shodan.example.internal", Proxy: proxy.URL},
+	}}
+
+	if c := httpopt.New("https://fofa.info", cfg.HTTPOptions("fofa")...); c.BaseURL != "https://fofa.example.internal" {
+		t.Errorf("fofa BaseURL = %s", c.BaseURL)
+	}
+	if c := httpopt.New("https://hunter.qianxin.com", cfg.HTTPOptions("hunter")...); c.BaseURL != "https://hunter.qianxin.com" {
+		t.Errorf("未配置的引擎 BaseURL = %s", c.BaseURL)
+	}
+
+	c := httpopt.New("https://api.shodan.io", cfg.HTTPOptions("shodan")...)
+	resp, err := c.HTTPClient.Get(c.BaseURL + "/api-info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if proxied != "http://shodan.example.internal/api-info" {
+		t.Errorf("proxy received %q", proxied)
+	}
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	tests := []struct {
+		endpoint Endpoint
+		want     string // 错误信息应包含的内容，为空表示有效
+	}{
+		{Endpoint{BaseURL: "https://fofa.example.internal", Proxy: "socks5://127.0.0.1:1080"}, ""},
+		{Endpoint{BaseURL: "fofa.example.internal"}, "base_url 无效"},
+		{Endpoint{BaseURL: "ftp://fofa.example.internal"}, "base_url 无效"},
+		{Endpoint{Proxy: "127.0.0.1:8080"}, "proxy 无效"},
+		{Endpoint{Proxy: "socks4://127.0.0.1:1080"}, "proxy 无效"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{
+			FofaEmail:  "user@example.com",
+			FofaAPIKey: "key",
+			MaxPage:    1,
+			PageSize:   10,
+			Endpoints:  map[string]Endpoint{"fofa": tt.endpoint},
+		}
+		err := validateConfig(cfg)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%+v: %v", tt.endpoint, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: error = %v, want containing %q", tt.endpoint, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// 域名校验和可注册域名提取，基于 golang.org/x/net/publicsuffix 内置的 Public Suffix List 快照
+
+// profile 将 IDN 转换为 punycode，并按 IDNA2008 规则校验每一段
+var profile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.ValidateLabels(true),
+	idna.StrictDomainName(true),
+	idna.Transitional(false),
+)
+
+// Error 域名校验失败的原因
+type Error struct {
+	Name   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("无效的域名 %s: %s", e.Name, e.Reason)
+}
+
+// ToASCII 将域名转换为小写的 ASCII 形式，中文等国际化域名转换为 punycode
+// 如 "例子.中国" -> "xn--fsqu00a.xn--fiqs8s"
+func ToASCII(name string) (string, error) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if name == "" {
+		return "", &Error{Name: name, Reason: "域名为空"}
+	}
+	// idna 不检查空的段，如 "a..com"
+	if strings.HasPrefix(name, ".") || strings.Contains(name, "..") {
+		return "", &Error{Name: name, Reason: "包含空的域名段"}
+	}
+	ascii, err := profile.ToASCII(name)
+	if err != nil {
+		return "", &Error{Name: name, Reason: "包含无效字符或格式错误"}
+	}
+	return strings.ToLower(ascii), nil
+}
+
+// ToUnicode 将 punycode 域名转换为便于阅读的 Unicode 形式，转换失败时原样返回
+func ToUnicode(name string) string {
+	u, err := idna.Display.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return u
+}
+
+// IsValid 判断是否为有效的域名
+// 要求至少两段、后缀在 Public Suffix List 中，且本身不是公共后缀（如 gov.hk）
+func IsValid(name string) bool {
+	_, err := Apex(name)
+	return err == nil
+}
+
+// Apex 返回可注册域名（eTLD+1），结果为 ASCII 形式
+// 如 "a.b.example.com.cn" -> "example.com.cn"，"foo.gov.hk" -> "foo.gov.hk"
+func Apex(name string) (string, error) {
+	ascii, err := ToASCII(name)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(ascii, ".") {
+		return "", &Error{Name: name, Reason: "缺少顶级域名"}
+	}
+
+	// 未收录的顶级域名会按默认规则 "*" 匹配，此时 icann 为 false 且后缀只有一段
+	suffix, icann := publicsuffix.PublicSuffix(ascii)
+	if !icann && !strings.Contains(suffix, ".") {
+		return "", &Error{Name: name, Reason: "未知的顶级域名 ." + suffix}
+	}
+
+	apex, err := publicsuffix.EffectiveTLDPlusOne(ascii)
+	if err != nil {
+		return "", &Error{Name: name, Reason: "本身是公共后缀"}
+	}
+	return apex, nil
+}
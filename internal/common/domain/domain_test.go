@@ -0,0 +1,61 @@
+package domain
+
+import "testing"
+
+func TestApex(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "a.b.example.com.cn", want: "example.com.cn"},
+		{name: " Example.COM. ", want: "example.com"},
+		{name: "foo.gov.hk", want: "foo.gov.hk"},
+		{name: "user.github.io", want: "user.github.io"},
+
+		// 国际化域名统一返回 punycode
+		{name: "例子.中国", want: "xn--fsqu00a.xn--fiqs8s"},
+		{name: "www.例子.中国", want: "xn--fsqu00a.xn--fiqs8s"},
+		{name: "xn--fsqu00a.xn--fiqs8s", want: "xn--fsqu00a.xn--fiqs8s"},
+		{name: "例子.公司.cn", want: "xn--fsqu00a.xn--55qx5d.cn"},
+		{name: "www.münchen.de", want: "xn--mnchen-3ya.de"},
+		{name: "Bücher.example.DE", want: "example.de"},
+		{name: "ＥＸＡＭＰＬＥ.com", want: "example.com"},
+
+		{name: "", wantErr: true},
+		{name: "com", wantErr: true},
+		{name: "gov.hk", wantErr: true},
+		{name: "github.io", wantErr: true},
+		{name: "中国", wantErr: true},
+		{name: "example.invalidtld", wantErr: true},
+		{name: "bad_name.com", wantErr: true},
+		{name: "-a.com", wantErr: true},
+		{name: "a..com", wantErr: true},
+		{name: ".example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Apex(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Apex(%q) = %q, want error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Apex(%q) error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Apex(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	if got := ToUnicode("xn--fsqu00a.xn--fiqs8s"); got != "例子.中国" {
+		t.Errorf("ToUnicode = %q, want 例子.中国", got)
+	}
+	if got := ToUnicode("example.com"); got != "example.com" {
+		t.Errorf("ToUnicode = %q, want example.com", got)
+	}
+}
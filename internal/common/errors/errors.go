@@ -1,6 +1,13 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // 定义错误类型
 type Error struct {
@@ -18,3 +25,199 @@ var (
 	ErrInvalidInput  = &Error{1002, "无效的输入"}
 	ErrAPIRequest    = &Error{2001, "API请求失败"}
 )
+
+// ErrQuotaUnknown 引擎没有独立的额度接口，运行前无法得知剩余额度
+var ErrQuotaUnknown = stderrors.New("剩余额度未知")
+
+// Kind 扫描器错误分类
+type Kind int
+
+const (
+	KindUnknown           Kind = iota // 未分类的错误
+	KindRateLimited                   // 请求过于频繁
+	KindQuotaExhausted                // 额度或积分耗尽
+	KindAuthFailed                    // API Key 无效或无权限
+	KindBadQuery                      // 查询语句错误
+	KindNetwork                       // 临时的网络或服务端错误
+	KindMalformedResponse             // 响应无法解析
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindRateLimited:
+		return "请求过于频繁"
+	case KindQuotaExhausted:
+		return "额度耗尽"
+	case KindAuthFailed:
+		return "鉴权失败"
+	case KindBadQuery:
+		return "查询语句错误"
+	case KindNetwork:
+		return "网络错误"
+	case KindMalformedResponse:
+		return "响应格式错误"
+	default:
+		return "API错误"
+	}
+}
+
+// ScanError 扫描器返回的分类错误
+type ScanError struct {
+	Kind       Kind
+	Source     string        // 引擎名称
+	Message    string        // 接口返回的错误信息
+	RetryAfter time.Duration // 服务端要求的等待时间，0 表示未指定
+	Err        error         // 底层错误
+}
+
+func (e *ScanError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s: %s", e.Source, e.Kind, msg)
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// New 创建分类错误
+func New(kind Kind, source, message string) *ScanError {
+	return &ScanError{Kind: kind, Source: source, Message: message}
+}
+
+// Wrap 将底层错误包装为分类错误
+func Wrap(kind Kind, source string, err error) *ScanError {
+	return &ScanError{Kind: kind, Source: source, Err: err}
+}
+
+// KindOf 返回错误的分类，非 ScanError 返回 KindUnknown
+func KindOf(err error) Kind {
+	var se *ScanError
+	if stderrors.As(err, &se) {
+		return se.Kind
+	}
+	return KindUnknown
+}
+
+// RetryAfterOf 返回错误中服务端要求的等待时间
+func RetryAfterOf(err error) time.Duration {
+	var se *ScanError
+	if stderrors.As(err, &se) {
+		return se.RetryAfter
+	}
+	return 0
+}
+
+// IsTransient 判断错误是否可以通过等待后重试解决
+// 响应无法解析通常是接口格式变化或返回了错误页面，重试只会重复消耗额度
+func IsTransient(err error) bool {
+	switch KindOf(err) {
+	case KindRateLimited, KindNetwork:
+		return true
+	default:
+		return false
+	}
+}
+
+// FromResponse 根据 HTTP 状态码返回分类错误，2xx 返回 nil
+func FromResponse(source string, resp *http.Response) *ScanError {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	kind := KindUnknown
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		kind = KindRateLimited
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		kind = KindAuthFailed
+	case resp.StatusCode == http.StatusPaymentRequired:
+		kind = KindQuotaExhausted
+	case resp.StatusCode == http.StatusBadRequest:
+		kind = KindBadQuery
+	case resp.StatusCode >= 500:
+		kind = KindNetwork
+	}
+
+	return &ScanError{
+		Kind:       kind,
+		Source:     source,
+		Message:    resp.Status,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// ParseRetryAfter 解析 Retry-After 头，支持秒数和 HTTP 日期两种格式
+func ParseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// KindFromMessage 根据接口返回的错误信息推断错误分类
+// 只在引擎没有可用的错误码或 HTTP 状态码时作为兜底，因此只匹配含义明确的短语，
+// 避免 "token"、"query" 这类普通词语造成误判
+func KindFromMessage(message string) Kind {
+	msg := strings.ToLower(message)
+	contains := func(patterns ...string) bool {
+		for _, p := range patterns {
+			if strings.Contains(msg, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains("rate limit", "too many requests", "请求过于频繁", "请求太频繁", "请求频率过高", "请求速度过快"):
+		return KindRateLimited
+	case contains("积分不足", "积分已用完", "余额不足", "额度不足", "额度已用完", "次数已用完",
+		"quota exceeded", "insufficient credits", "insufficient query credits", "no query credits"):
+		return KindQuotaExhausted
+	case contains("account invalid", "invalid api key", "invalid api-key", "invalid apikey", "api key is invalid",
+		"valid api key", "unauthorized", "无权限", "未授权", "认证失败"):
+		return KindAuthFailed
+	case contains("语法错误", "syntax error", "查询语句错误", "invalid query", "invalid search query", "参数错误"):
+		return KindBadQuery
+	case contains("稍后再试"):
+		// 没有给出更具体原因的 "请稍后再试" 通常是服务端限流
+		return KindRateLimited
+	default:
+		return KindUnknown
+	}
+}
+
+// FromAPI 根据接口返回的错误信息创建分类错误
+// 优先使用 HTTP 状态码分类，状态码无法分类（如接口总是返回 200）时才根据错误信息推断，
+// 并记录响应中的 Retry-After；引擎自己的错误码应在调用后覆盖 Kind
+func FromAPI(source string, resp *http.Response, message string) *ScanError {
+	se := &ScanError{
+		Source:  source,
+		Message: message,
+	}
+	if resp != nil {
+		if statusErr := FromResponse(source, resp); statusErr != nil {
+			se.Kind = statusErr.Kind
+			se.RetryAfter = statusErr.RetryAfter
+		}
+		if se.RetryAfter == 0 {
+			se.RetryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+	}
+	if se.Kind == KindUnknown {
+		se.Kind = KindFromMessage(message)
+	}
+	return se
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestKindFromMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Kind
+	}{
+		{"请求过于频繁，请稍后再试", KindRateLimited},
+		{"Rate limit reached", KindRateLimited},
+		{"服务繁忙，请稍后再试", KindRateLimited},
+		{"额度不足，请充值或稍后再试", KindQuotaExhausted},
+		{"F点余额不足", KindQuotaExhausted},
+		{"今日免费积分已用完", KindQuotaExhausted},
+		{"[-700] Account Invalid", KindAuthFailed},
+		{"Please provide a valid API key.", KindAuthFailed},
+		{"查询语句错误", KindBadQuery},
+		{"Invalid search query", KindBadQuery},
+		// 只是包含普通词语的信息不应被归类
+		{"token 已刷新", KindUnknown},
+		{"query completed with no results", KindUnknown},
+		{"访问频繁度统计", KindUnknown},
+		{"request id 4291", KindUnknown},
+		{"credit card required for export", KindUnknown},
+	}
+	for _, tt := range tests {
+		if got := KindFromMessage(tt.message); got != tt.want {
+			t.Errorf("KindFromMessage(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestFromAPIPrefersStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+		want    Kind
+	}{
+		// 状态码优先于错误信息
+		{http.StatusTooManyRequests, "查询语句错误", KindRateLimited},
+		{http.StatusUnauthorized, "请求过于频繁", KindAuthFailed},
+		{http.StatusServiceUnavailable, "", KindNetwork},
+		// 接口总是返回 200 时根据错误信息推断
+		{http.StatusOK, "余额不足", KindQuotaExhausted},
+		{http.StatusOK, "unexpected", KindUnknown},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Status: http.StatusText(tt.status), Header: http.Header{}}
+		if got := FromAPI("Test", resp, tt.message).Kind; got != tt.want {
+			t.Errorf("FromAPI(%d, %q).Kind = %v, want %v", tt.status, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want bool
+	}{
+		{KindRateLimited, true},
+		{KindNetwork, true},
+		{KindMalformedResponse, false},
+		{KindQuotaExhausted, false},
+		{KindAuthFailed, false},
+		{KindBadQuery, false},
+		{KindUnknown, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(New(tt.kind, "Test", "")); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,16 @@
 package excel
 
 import (
-	"bufio"
-	"cscan/internal/common/model"
+	"cscan/internal/common/domain"
+	"cscan/internal/common/logger"
 	"cscan/internal/cse"
-	"fmt"
-	"os"
+	"errors"
+	"net/netip"
 	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
-
-	"github.com/xuri/excelize/v2"
+	"unicode/utf8"
 )
 
 // 添加错误常量
@@ -20,129 +19,170 @@ const (
 	ErrEmptyFile     = "文件为空"
 )
 
-// ReadTargets 从文本文件读取目标
-func ReadTargets(filename string) ([]cse.Target, error) {
-	if !strings.HasSuffix(filename, ".txt") {
-		return nil, fmt.Errorf(ErrInvalidFormat)
-	}
+// 域名目标的规范化方式
+const (
+	NormalizeApex = "apex" // 只保留可注册域名，如 vpn.corp.example.com -> example.com（默认）
+	NormalizeHost = "host" // 保留完整主机名，按主机精确搜索
+	NormalizeBoth = "both" // 同时搜索可注册域名和完整主机名
+)
 
-	content, err := os.ReadFile(filename)
+// ReadTargets 从输入文件读取目标，支持 txt、csv、xlsx 和 json 格式，opts 指定表格读取的工作表和列
+// normalize 指定域名的规范化方式，为空时使用 NormalizeApex
+// 返回的解析报告记录了无法识别而被丢弃的内容
+func ReadTargets(filename, normalize string, opts InputOptions) ([]cse.Target, *ParseReport, error) {
+	records, err := readRecords(filename, opts)
 	if err != nil {
-		return nil, err
-	}
-
-	if len(content) == 0 {
-		return nil, fmt.Errorf(ErrEmptyFile)
+		return nil, nil, err
 	}
 
 	// 用于去重的map
 	seen := make(map[string]bool)
 	var targets []cse.Target
+	report := &ParseReport{File: filename}
 
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
+	for _, rec := range records {
+		line := strings.TrimSpace(rec.text)
 		if line == "" || line[0] == '#' { // 跳过空行和注释
 			continue
 		}
 
 		// 处理每一行内容
-		cellTargets := parseTargets(line)
+		cellTargets, rejected := parseTargets(line, normalize)
+		for _, rej := range rejected {
+			report.reject(rec.line, rej.Token, rej.Reason)
+		}
 		for _, target := range cellTargets {
-			if !seen[target.Value] {
+			if seen[target.Value] {
+				report.Duplicates++
+			} else {
 				seen[target.Value] = true
 				targets = append(targets, target)
-				fmt.Printf("添加唯一目标: %s (%s)\n", target.Value, target.Type)
+				if display := domain.ToUnicode(target.Value); (target.Type == "domain" || target.Type == "host") && display != target.Value {
+					logger.Printf("添加唯一目标: %s [%s] (%s)\n", target.Value, display, target.Type)
+				} else {
+					logger.Printf("添加唯一目标: %s (%s)\n", target.Value, target.Type)
+				}
 			}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("读取文件失败: %v", err)
+	report.Accepted = len(targets)
+	if len(targets) == 0 {
+		logger.Println("警告: 未找到任何有效目标，请确保target.txt文件存在且包含有效内容")
+	} else {
+		logger.Printf("共读取到 %d 个唯一目标\n", len(targets))
 	}
+	return targets, report, nil
+}
 
-	if len(targets) == 0 {
-		fmt.Println("警告: 未找到任何有效目标，请确保target.txt文件存在且包含有效内容")
+// ReadQueries 从输入文件读取原生查询语句，每行（单元格）一条，可用 "引擎名:" 前缀指定引擎
+func ReadQueries(filename string, opts InputOptions) ([]cse.Target, error) {
+	records, err := readRecords(filename, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var queries []cse.Target
+
+	for _, rec := range records {
+		line := strings.TrimSpace(rec.text)
+		if line == "" || line[0] == '#' { // 跳过空行和注释
+			continue
+		}
+
+		query := cse.ParseQuery(line)
+		if query.Value == "" {
+			continue
+		}
+		key := query.Engine + "|" + query.Value
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		queries = append(queries, query)
+	}
+
+	if len(queries) == 0 {
+		logger.Println("警告: 未找到任何查询语句")
 	} else {
-		fmt.Printf("共读取到 %d 个唯一目标\n", len(targets))
+		logger.Printf("共读取到 %d 条查询语句\n", len(queries))
 	}
-	return targets, nil
+	return queries, nil
 }
 
-// ReadCompanies 从文本文件读取公司名称
-func ReadCompanies(filename string) ([]string, error) {
-	// 读取文件内容
-	file, err := os.Open(filename)
+// ReadCompanies 从输入文件读取公司名称，支持的格式与 ReadTargets 相同
+// 返回的解析报告记录了不像公司名称而被丢弃的行
+func ReadCompanies(filename string, opts InputOptions) ([]string, *ParseReport, error) {
+	records, err := readRecords(filename, opts)
 	if err != nil {
-		return nil, fmt.Errorf("打开文件失败: %v", err)
+		return nil, nil, err
 	}
-	defer file.Close()
 
 	var companies []string
 	seen := make(map[string]bool)
+	report := &ParseReport{File: filename}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		company := strings.TrimSpace(scanner.Text())
-		// 跳过空行、注释和明显不是公司名的内容
-		if company == "" || company[0] == '#' || strings.Contains(company, "PK") || strings.Contains(company, ".xml") {
+	for _, rec := range records {
+		company := strings.TrimSpace(rec.text)
+		// 跳过空行和注释
+		if company == "" || company[0] == '#' {
 			continue
 		}
 
 		// 基本的公司名称验证
-		if isValidCompanyName(company) {
-			if !seen[company] {
-				seen[company] = true
-				companies = append(companies, company)
-				fmt.Printf("添加公司: %s\n", company)
-			}
+		if reason := companyRejectReason(company); reason != "" {
+			report.reject(rec.line, company, reason)
+			continue
 		}
+		if seen[company] {
+			report.Duplicates++
+			continue
+		}
+		seen[company] = true
+		companies = append(companies, company)
+		logger.Printf("添加公司: %s\n", company)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("读取文件失败: %v", err)
-	}
-
+	report.Accepted = len(companies)
 	if len(companies) == 0 {
-		fmt.Println("警告: 未找到任何有效公司名称，请确保target.txt文件存在且包含有效内容")
+		logger.Println("警告: 未找到任何有效公司名称，请确保target.txt文件存在且包含有效内容")
 	} else {
-		fmt.Printf("共读取到 %d 个公司\n", len(companies))
+		logger.Printf("共读取到 %d 个公司\n", len(companies))
 	}
-	return companies, nil
+	return companies, report, nil
 }
 
-// isValidCompanyName 验证公司名称的有效性
-func isValidCompanyName(name string) bool {
+// companySuffixPattern 英文公司名称的常见后缀，按完整单词匹配，避免 "Co" 匹配到 "Cook" 等单词
+var companySuffixPattern = regexp.MustCompile(`(?i)\b(corporation|corp|inc|ltd|limited|llc|co|company|group|holdings?|gmbh|plc)\b`)
+
+// companyRejectReason 返回不像公司名称的原因，有效时返回空字符串
+func companyRejectReason(name string) string {
+	// ZIP/XLSX 等二进制文件被当作文本读取时以 "PK\x03\x04" 开头，或包含控制字符
+	if strings.HasPrefix(name, "PK\x03\x04") || strings.ContainsAny(name, "\x00\ufffd") {
+		return "疑似二进制文件内容"
+	}
+	if strings.Contains(name, "<?xml") || strings.Contains(name, ".xml") {
+		return "疑似 XML 内容"
+	}
+
 	// 跳过明显的非公司名内容
-	invalidPatterns := []string{
-		"PK",
-		".xml",
-		"<?xml",
-		"[]",
-		"{}",
-		"<",
-		">",
-		"@",
-		"http",
-		".com",
-		".cn",
-		".jp",
-		".org",
-		".net",
-	}
-
-	for _, pattern := range invalidPatterns {
+	for _, pattern := range []string{"[]", "{}", "<", ">", "@"} {
 		if strings.Contains(name, pattern) {
-			return false
+			return "包含无效字符 " + pattern
+		}
+	}
+	lower := strings.ToLower(name)
+	for _, pattern := range []string{"http", ".com", ".cn", ".jp", ".org", ".net"} {
+		if strings.Contains(lower, pattern) {
+			return "疑似网址或域名"
 		}
 	}
 
 	// 公司名称的基本验证规则
 	// 1. 长度至少2个字符
-	if len(name) < 2 {
-		return false
+	if utf8.RuneCountInString(name) < 2 {
+		return "长度过短"
 	}
 
 	// 2. 不能只包含数字和符号
@@ -154,33 +194,18 @@ func isValidCompanyName(name string) bool {
 		}
 	}
 	if !hasLetter {
-		return false
+		return "不包含文字"
 	}
 
 	// 3. 常见的公司名称后缀
-	companySuffixes := []string{
-		"公司",
-		"集团",
-		"有限",
-		"股份",
-		"企业",
-		"工厂",
-		"厂",
-		"Corporation",
-		"Corp",
-		"Inc",
-		"Ltd",
-		"Limited",
-		"LLC",
-		"Co",
-	}
-
-	// 检查是否包含常见的公司名称后缀
-	for _, suffix := range companySuffixes {
+	for _, suffix := range []string{"公司", "集团", "有限", "股份", "企业", "工厂", "厂"} {
 		if strings.Contains(name, suffix) {
-			return true
+			return ""
 		}
 	}
+	if companySuffixPattern.MatchString(name) {
+		return ""
+	}
 
 	// 如果没有明显的公司后缀，但看起来像是中文名称（包含至少2个汉字）
 	chineseCount := 0
@@ -189,49 +214,27 @@ func isValidCompanyName(name string) bool {
 			chineseCount++
 		}
 	}
-	return chineseCount >= 2
-}
-
-// normalizeDomain 规范化域名，只保留合适的层级
-func normalizeDomain(domain string) string {
-	// 分割域名
-	parts := strings.Split(domain, ".")
-	if len(parts) <= 2 {
-		return domain // 如果只有两级或更少，直接返回
-	}
-
-	// 处理特殊的二级域名后缀
-	specialTLDs := map[string]bool{
-		"com.cn": true,
-		"org.cn": true,
-		"net.cn": true,
-		"gov.cn": true,
-		"edu.cn": true,
-		"co.jp":  true,
-		"co.uk":  true,
-		// 可以根据需要添加更多
-	}
-
-	// 检查是否有特殊的二级域名后缀
-	lastTwo := strings.Join(parts[len(parts)-2:], ".")
-	if specialTLDs[lastTwo] {
-		if len(parts) > 3 {
-			// 返回倒数第三级 + 特殊后缀
-			return strings.Join(parts[len(parts)-3:], ".")
-		}
-		return domain
+	if chineseCount >= 2 {
+		return ""
 	}
+	return "缺少公司名称特征（如 公司、集团、Ltd、Inc）"
+}
 
-	// 普通域名，返回倒数第二级 + 顶级域名
-	if len(parts) > 2 {
-		return strings.Join(parts[len(parts)-2:], ".")
+// normalizeDomain 规范化域名，返回可注册域名（如 a.b.example.com.cn -> example.com.cn）
+func normalizeDomain(name string) string {
+	apex, err := domain.Apex(name)
+	if err != nil {
+		return name
 	}
-	return domain
+	return apex
 }
 
-// parseTargets 解析内容，返回所有有效的目标
-func parseTargets(content string) []cse.Target {
-	var targets []cse.Target
+// parseTargets 解析内容，返回所有有效的目标和无法识别的内容（行号由调用方填写）
+func parseTargets(content, normalize string) ([]cse.Target, []Rejection) {
+	var (
+		targets  []cse.Target
+		rejected []Rejection
+	)
 
 	// 处理所有可能的分隔符，包括换行符
 	parts := strings.FieldsFunc(content, func(r rune) bool {
@@ -243,10 +246,44 @@ func parseTargets(content string) []cse.Target {
 		if part == "" {
 			continue
 		}
+		token := part
+
+		// 网段、IP 范围和 ASN 包含斜杠或连字符，需要在清理 URL 之前识别
+		if target, ok := parseNetworkTarget(part); ok {
+			targets = append(targets, target)
+			continue
+		}
+		// 形如网段但无法解析，避免清理 URL 时截断为单个 IP
+		if looksLikeCIDR(token) {
+			rejected = append(rejected, Rejection{Token: token, Reason: "无效的网段"})
+			continue
+		}
 
 		// 处理可能的URL格式
 		part = cleanURL(part)
 
+		// 带方括号的 IPv6 地址，如 http://[2001:db8::1]:8080/
+		if strings.HasPrefix(part, "[") {
+			if end := strings.Index(part, "]"); end > 0 {
+				part = part[1:end]
+			}
+		}
+		if addr, err := netip.ParseAddr(part); err == nil && addr.Is6() {
+			// IPv4 映射地址按 IPv4 处理
+			if addr.Is4In6() {
+				targets = append(targets, cse.Target{
+					Value: addr.Unmap().String(),
+					Type:  "ip",
+				})
+				continue
+			}
+			targets = append(targets, cse.Target{
+				Value: addr.String(),
+				Type:  "ipv6",
+			})
+			continue
+		}
+
 		// 尝试提取IP地址
 		if isValidIP(part) {
 			targets = append(targets, cse.Target{
@@ -257,27 +294,92 @@ func parseTargets(content string) []cse.Target {
 		}
 
 		// 尝试提取域名
-		if isDomain(part) {
-			// 规范化域名
-			normalizedDomain := normalizeDomain(part)
-			// 检查是否已经添加过这个域名
-			exists := false
-			for _, t := range targets {
-				if t.Type == "domain" && t.Value == normalizedDomain {
-					exists = true
-					break
-				}
-			}
-			if !exists {
-				targets = append(targets, cse.Target{
-					Value: normalizedDomain,
-					Type:  "domain",
-				})
-			}
+		if !isDomain(part) {
+			rejected = append(rejected, Rejection{Token: token, Reason: targetRejectReason(token, part)})
+			continue
 		}
+
+		// 规范化域名
+		normalizedDomain := normalizeDomain(part)
+		host, err := domain.ToASCII(part)
+		if err != nil {
+			host = normalizedDomain
+		}
+
+		// host 模式只保留完整主机名；both 模式在主机名与可注册域名不同时两者都保留
+		if normalize != NormalizeHost {
+			targets = appendTarget(targets, cse.Target{Value: normalizedDomain, Type: "domain"})
+		}
+		if normalize == NormalizeHost || (normalize == NormalizeBoth && host != normalizedDomain) {
+			targets = appendTarget(targets, cse.Target{Value: host, Type: "host"})
+		}
+	}
+
+	return targets, rejected
+}
+
+// targetRejectReason 返回无法识别为目标的原因，token 为原始内容，cleaned 为清理 URL 后的内容
+func targetRejectReason(token, cleaned string) string {
+	isIPLike := cleaned != "" && strings.Trim(cleaned, "0123456789.") == ""
+	switch {
+	case isIPLike && strings.Count(cleaned, ".") == 3:
+		return "无效的 IP 地址"
+	case strings.Contains(token, "-") && strings.Trim(token, "0123456789.-:abcdefABCDEF") == "":
+		return "无效的 IP 范围"
+	case isIPLike:
+		return "无法识别为 IP 或域名"
 	}
 
-	return targets
+	var domainErr *domain.Error
+	if _, err := domain.Apex(cleaned); errors.As(err, &domainErr) {
+		return "无效的域名: " + domainErr.Reason
+	}
+	return "无法识别为 IP、网段或域名"
+}
+
+// looksLikeCIDR 判断内容是否形如网段，即斜杠前是 IP 地址、斜杠后是数字
+func looksLikeCIDR(token string) bool {
+	addr, bits, ok := strings.Cut(token, "/")
+	if !ok || bits == "" || strings.Trim(bits, "0123456789") != "" {
+		return false
+	}
+	_, err := netip.ParseAddr(addr)
+	return err == nil
+}
+
+// asnPattern 自治系统号，如 AS12345
+var asnPattern = regexp.MustCompile(`(?i)^AS(\d+)$`)
+
+// parseNetworkTarget 识别网段 (10.0.0.0/24)、IP 范围 (1.1.1.1-1.1.1.50) 和 ASN (AS12345)
+func parseNetworkTarget(part string) (cse.Target, bool) {
+	if m := asnPattern.FindStringSubmatch(part); m != nil {
+		return cse.Target{Value: m[1], Type: "asn"}, true
+	}
+
+	if strings.Contains(part, "/") {
+		if prefix, err := netip.ParsePrefix(part); err == nil {
+			// 统一为网络地址，如 10.0.0.1/24 -> 10.0.0.0/24
+			return cse.Target{Value: prefix.Masked().String(), Type: "cidr"}, true
+		}
+	}
+
+	if strings.Contains(part, "-") {
+		if start, end, err := cse.ParseRange(part); err == nil {
+			return cse.Target{Value: start.String() + "-" + end.String(), Type: "range"}, true
+		}
+	}
+
+	return cse.Target{}, false
+}
+
+// appendTarget 添加目标，已存在相同类型和值的目标时跳过
+func appendTarget(targets []cse.Target, target cse.Target) []cse.Target {
+	for _, t := range targets {
+		if t.Type == target.Type && t.Value == target.Value {
+			return targets
+		}
+	}
+	return append(targets, target)
 }
 
 // cleanURL 清理URL，提取域名部分
@@ -285,7 +387,6 @@ func cleanURL(url string) string {
 	// 移除协议前缀
 	url = strings.TrimPrefix(url, "http://")
 	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimPrefix(url, "www.")
 
 	// 如果包含路径，只保留域名部分
 	if idx := strings.Index(url, "/"); idx != -1 {
@@ -310,149 +411,11 @@ func isValidIP(ip string) bool {
 	return true
 }
 
-// isDomain 验证域名的有效性
-func isDomain(domain string) bool {
-	// 域名的基本验证规则
-	domainPattern := `^([a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$`
-	matched, _ := regexp.MatchString(domainPattern, domain)
-
-	// 额外检查常见的无效域名情况
-	if !matched {
-		return false
-	}
-
+// isDomain 验证域名的有效性，支持国际化域名和 Public Suffix List 中的所有后缀
+func isDomain(name string) bool {
 	// 确保不是IP地址格式
-	if isValidIP(domain) {
+	if isValidIP(name) {
 		return false
 	}
-
-	// 检查是否包含有效的顶级域名
-	parts := strings.Split(domain, ".")
-	if len(parts) < 2 {
-		return false
-	}
-
-	// 检查顶级域名是否合法
-	tld := parts[len(parts)-1]
-	validTLDs := map[string]bool{
-		"com": true, "net": true, "org": true, "edu": true, "gov": true,
-		"cn": true, "jp": true, "uk": true, "ru": true, "de": true,
-		"fr": true, "br": true, "in": true, "au": true, "info": true,
-		"biz": true, "io": true, "co": true, "me": true, "tv": true,
-		// 可以根据需要添加更多有效的顶级域名
-	}
-
-	return validTLDs[tld]
-}
-
-// SaveResults 将结果保存到Excel文件，并进行去重和添加筛选功能
-func SaveResults(results []model.Asset, filename string) error {
-	f := excelize.NewFile()
-	defer f.Close()
-
-	// 设置表头
-	headers := []string{"IP", "域名", "端口", "服务", "标题", "状态码", "ICP主体", "地理位置", "来源"}
-	for i, header := range headers {
-		cell := fmt.Sprintf("%c1", 'A'+i)
-		f.SetCellValue("Sheet1", cell, header)
-	}
-
-	// 去重处理
-	uniqueResults := deduplicateAssets(results)
-	fmt.Printf("去重前: %d 条记录, 去重后: %d 条记录\n", len(results), len(uniqueResults))
-
-	// 写入数据
-	for i, asset := range uniqueResults {
-		row := i + 2
-		f.SetCellValue("Sheet1", fmt.Sprintf("A%d", row), asset.IP)
-		f.SetCellValue("Sheet1", fmt.Sprintf("B%d", row), asset.Domain)
-		f.SetCellValue("Sheet1", fmt.Sprintf("C%d", row), asset.Port)
-		f.SetCellValue("Sheet1", fmt.Sprintf("D%d", row), asset.Service)
-		f.SetCellValue("Sheet1", fmt.Sprintf("E%d", row), asset.Title)
-		f.SetCellValue("Sheet1", fmt.Sprintf("F%d", row), asset.StatusCode)
-		f.SetCellValue("Sheet1", fmt.Sprintf("G%d", row), asset.ICPOrg)
-		f.SetCellValue("Sheet1", fmt.Sprintf("H%d", row), asset.Location)
-		f.SetCellValue("Sheet1", fmt.Sprintf("I%d", row), asset.Source)
-	}
-
-	// 添加筛选功能
-	lastRow := len(uniqueResults) + 1
-	if lastRow < 2 {
-		lastRow = 2 // 确保至少有一行数据
-	}
-
-	// 设置自动筛选
-	if err := f.AutoFilter("Sheet1", fmt.Sprintf("A1:I%d", lastRow), []excelize.AutoFilterOptions{}); err != nil {
-		return fmt.Errorf("设置筛选失败: %v", err)
-	}
-
-	// 调整列宽以适应内容
-	for i := 0; i < len(headers); i++ {
-		col := string('A' + i)
-		if err := f.SetColWidth("Sheet1", col, col, 20); err != nil {
-			fmt.Printf("设置列宽失败 %s: %v\n", col, err)
-		}
-	}
-
-	// 冻结首行
-	if err := f.SetPanes("Sheet1", &excelize.Panes{
-		Freeze:      true,
-		Split:       false,
-		XSplit:      0,
-		YSplit:      1,
-		TopLeftCell: "A2",
-		ActivePane:  "bottomLeft",
-	}); err != nil {
-		fmt.Printf("冻结首行失败: %v\n", err)
-	}
-
-	// 保存文件
-	return f.SaveAs(filename)
-}
-
-// deduplicateAssets 对资产进行去重
-func deduplicateAssets(assets []model.Asset) []model.Asset {
-	seen := make(map[string]bool)
-	var result []model.Asset
-
-	for _, asset := range assets {
-		// 生成唯一标识
-		key := generateAssetKey(asset)
-		if !seen[key] {
-			seen[key] = true
-			result = append(result, asset)
-		}
-	}
-
-	return result
-}
-
-// generateAssetKey 生成资产的唯一标识
-func generateAssetKey(asset model.Asset) string {
-	// 如果有IP和端口，使用"IP:端口"作为key
-	if asset.IP != "" && asset.Port != "" {
-		return fmt.Sprintf("%s:%s", asset.IP, asset.Port)
-	}
-	// 如果有域名，使用域名作为key
-	if asset.Domain != "" {
-		return asset.Domain
-	}
-	// 如果都没有，使用所有非空字段组合
-	parts := []string{
-		asset.IP,
-		asset.Domain,
-		asset.Port,
-		asset.Service,
-		asset.Title,
-		asset.StatusCode,
-		asset.ICPOrg,
-		asset.Location,
-	}
-	var nonEmpty []string
-	for _, part := range parts {
-		if part != "" {
-			nonEmpty = append(nonEmpty, part)
-		}
-	}
-	return strings.Join(nonEmpty, "|")
+	return domain.IsValid(name)
 }
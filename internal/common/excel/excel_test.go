@@ -0,0 +1,146 @@
+package excel
+
+import (
+	"cscan/internal/cse"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		content   string
+		normalize string
+		want      []cse.Target
+		rejected  []Rejection
+	}{
+		{
+			content: "1.1.1.1, example.com；10.0.0.1/24",
+			want: []cse.Target{
+				{Value: "1.1.1.1", Type: "ip"},
+				{Value: "example.com", Type: "domain"},
+				{Value: "10.0.0.0/24", Type: "cidr"},
+			},
+		},
+		{
+			content: "https://vpn.corp.example.com/login",
+			want:    []cse.Target{{Value: "example.com", Type: "domain"}},
+		},
+		{
+			content:   "https://vpn.corp.example.com/login",
+			normalize: NormalizeHost,
+			want:      []cse.Target{{Value: "vpn.corp.example.com", Type: "host"}},
+		},
+		{
+			// 同一单元格内的重复目标只保留一个
+			content:   "a.example.co.uk example.co.uk",
+			normalize: NormalizeBoth,
+			want: []cse.Target{
+				{Value: "example.co.uk", Type: "domain"},
+				{Value: "a.example.co.uk", Type: "host"},
+			},
+		},
+		{
+			content: "例子.中国",
+			want:    []cse.Target{{Value: "xn--fsqu00a.xn--fiqs8s", Type: "domain"}},
+		},
+		{
+			content: "http://[2001:db8::1]:8080/ ::ffff:1.2.3.4",
+			want: []cse.Target{
+				{Value: "2001:db8::1", Type: "ipv6"},
+				{Value: "1.2.3.4", Type: "ip"},
+			},
+		},
+		{
+			content: "1.1.1.1-50\tAS13335",
+			want: []cse.Target{
+				{Value: "1.1.1.1-1.1.1.50", Type: "range"},
+				{Value: "13335", Type: "asn"},
+			},
+		},
+		{
+			content: "1.1.1.256 1.1.1.1/33 1.1.1.9-1.1.1.1 foo example.invalidtld gov.hk",
+			rejected: []Rejection{
+				{Token: "1.1.1.256", Reason: "无效的 IP 地址"},
+				{Token: "1.1.1.1/33", Reason: "无效的网段"},
+				{Token: "1.1.1.9-1.1.1.1", Reason: "无效的 IP 范围"},
+				{Token: "foo", Reason: "无效的域名: 缺少顶级域名"},
+				{Token: "example.invalidtld", Reason: "无效的域名: 未知的顶级域名 .invalidtld"},
+				{Token: "gov.hk", Reason: "无效的域名: 本身是公共后缀"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, rejected := parseTargets(tt.content, tt.normalize)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTargets(%q, %q) = %v, want %v", tt.content, tt.normalize, got, tt.want)
+		}
+		if !reflect.DeepEqual(rejected, tt.rejected) {
+			t.Errorf("parseTargets(%q, %q) rejected = %v, want %v", tt.content, tt.normalize, rejected, tt.rejected)
+		}
+	}
+}
+
+func TestLooksLikeCIDR(t *testing.T) {
+	tests := map[string]bool{
+		"10.0.0.0/24":          true,
+		"10.0.0.0/33":          true,
+		"2001:db8::/129":       true,
+		"10.0.0.0/":            false,
+		"10.0.0.0/abc":         false,
+		"example.com/24":       false,
+		"http://example.com/1": false,
+	}
+	for token, want := range tests {
+		if got := looksLikeCIDR(token); got != want {
+			t.Errorf("looksLikeCIDR(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
+
+func TestReadTargetsReport(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "targets.txt")
+	content := "# 注释\n1.1.1.1\nexample.com, www.example.com\n\nnot_a_domain\n1.1.1.1 999.1.1.1\n"
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, report, err := ReadTargets(input, "", InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []cse.Target{
+		{Value: "1.1.1.1", Type: "ip"},
+		{Value: "example.com", Type: "domain"},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %v, want %v", targets, want)
+	}
+	if report.Accepted != 2 || report.Duplicates != 1 {
+		t.Errorf("Accepted = %d, Duplicates = %d, want 2, 1", report.Accepted, report.Duplicates)
+	}
+	wantRejected := []Rejection{
+		{Line: 5, Token: "not_a_domain", Reason: "无效的域名: 包含无效字符或格式错误"},
+		{Line: 6, Token: "999.1.1.1", Reason: "无效的 IP 地址"},
+	}
+	if !reflect.DeepEqual(report.Rejected, wantRejected) {
+		t.Errorf("Rejected = %v, want %v", report.Rejected, wantRejected)
+	}
+
+	rejects := filepath.Join(dir, "rejects.txt")
+	if err := report.WriteRejects(rejects); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(rejects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFile := "# " + input + " 中被丢弃的内容\n" +
+		"5\tnot_a_domain\t无效的域名: 包含无效字符或格式错误\n" +
+		"6\t999.1.1.1\t无效的 IP 地址\n"
+	if string(data) != wantFile {
+		t.Errorf("rejects file = %q, want %q", data, wantFile)
+	}
+}
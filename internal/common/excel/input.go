@@ -0,0 +1,287 @@
+package excel
+
+import (
+	"cscan/internal/common/logger"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// InputOptions 表格类输入文件的读取选项，对 .txt 文件无效
+type InputOptions struct {
+	Sheet  string // XLSX 工作表名称，为空时使用第一个工作表
+	Column string // 读取的列：CSV/XLSX 为表头名称（XLSX 也可用列号如 B），JSON 为对象字段名；为空时读取所有单元格
+}
+
+// headerNames 常见的表头名称，未指定列时第一行全部由这些名称组成则视为表头并跳过
+var headerNames = map[string]bool{
+	"target": true, "targets": true, "domain": true, "domains": true,
+	"ip": true, "ips": true, "host": true, "hosts": true, "hostname": true,
+	"url": true, "urls": true, "asset": true, "assets": true, "cidr": true, "asn": true,
+	"query": true, "queries": true, "company": true, "name": true, "org": true,
+	"目标": true, "域名": true, "主机": true, "网址": true, "资产": true, "网段": true,
+	"查询": true, "查询语句": true, "公司": true, "公司名称": true, "企业名称": true, "单位名称": true, "名称": true,
+}
+
+// record 输入文件中的一条内容，line 为所在行号（JSON 为数组中的序号）
+type record struct {
+	line int
+	text string
+}
+
+// SupportedInputs 支持的输入文件格式
+var SupportedInputs = []string{".txt", ".csv", ".xlsx", ".json"}
+
+// IsSupportedInput 判断文件扩展名是否为支持的输入格式，"-" 表示标准输入
+func IsSupportedInput(filename string) bool {
+	if filename == "-" {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, supported := range SupportedInputs {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// readRecords 按扩展名读取输入文件，返回逐条内容，filename 为 "-" 时按文本格式读取标准输入
+func readRecords(filename string, opts InputOptions) ([]record, error) {
+	if filename == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("读取标准输入失败: %v", err)
+		}
+		return textRecords(content)
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".txt":
+		return readTextRecords(filename)
+	case ".csv":
+		return readCSVRecords(filename, opts)
+	case ".xlsx":
+		return readXLSXRecords(filename, opts)
+	case ".json":
+		return readJSONRecords(filename, opts)
+	default:
+		return nil, fmt.Errorf("%s，支持的格式: %s", ErrInvalidFormat, strings.Join(SupportedInputs, ", "))
+	}
+}
+
+// readTextRecords 读取文本文件，每行一条
+func readTextRecords(filename string) ([]record, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return textRecords(content)
+}
+
+// textRecords 将文本内容按行拆分
+func textRecords(content []byte) ([]record, error) {
+	if len(content) == 0 {
+		return nil, fmt.Errorf(ErrEmptyFile)
+	}
+
+	lines := strings.Split(strings.TrimPrefix(string(content), "\ufeff"), "\n")
+	records := make([]record, 0, len(lines))
+	for i, line := range lines {
+		records = append(records, record{line: i + 1, text: line})
+	}
+	return records, nil
+}
+
+// readCSVRecords 读取 CSV 文件，指定列时第一行为表头
+func readCSVRecords(filename string, opts InputOptions) ([]record, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // 允许各行列数不同
+	reader.LazyQuotes = true
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 CSV 失败: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf(ErrEmptyFile)
+	}
+	// 去掉 Excel 导出的 UTF-8 BOM
+	if len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+
+	return tableRecords(rows, opts.Column, false)
+}
+
+// readXLSXRecords 读取 XLSX 工作表，指定列时第一行为表头
+func readXLSXRecords(filename string, opts InputOptions) ([]record, error) {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheet := opts.Sheet
+	sheets := f.GetSheetList()
+	if sheet == "" {
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf(ErrEmptyFile)
+		}
+		sheet = sheets[0]
+	} else if idx, _ := f.GetSheetIndex(sheet); idx < 0 {
+		return nil, fmt.Errorf("工作表 %s 不存在，可用的工作表: %s", sheet, strings.Join(sheets, ", "))
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("读取工作表 %s 失败: %v", sheet, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf(ErrEmptyFile)
+	}
+
+	return tableRecords(rows, opts.Column, true)
+}
+
+// tableRecords 从表格行中提取内容，column 为空时读取所有单元格，第一行是表头时跳过
+// allowLetter 为 true 时 column 也可以是列号（如 B），此时不跳过第一行
+func tableRecords(rows [][]string, column string, allowLetter bool) ([]record, error) {
+	var records []record
+	if column == "" {
+		start := 0
+		if isHeaderRow(rows[0]) {
+			logger.Printf("跳过表头: %s\n", strings.Join(rows[0], ", "))
+			start = 1
+		}
+		for i := start; i < len(rows); i++ {
+			for _, cell := range rows[i] {
+				records = append(records, record{line: i + 1, text: cell})
+			}
+		}
+		return records, nil
+	}
+
+	start := 1 // 跳过表头
+	index := headerIndex(rows[0], column)
+	if index < 0 && allowLetter {
+		if num, err := excelize.ColumnNameToNumber(column); err == nil {
+			index, start = num-1, 0
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("未找到列 %s，表头为: %s", column, strings.Join(rows[0], ", "))
+	}
+
+	for i := start; i < len(rows); i++ {
+		if index < len(rows[i]) {
+			records = append(records, record{line: i + 1, text: rows[i][index]})
+		}
+	}
+	return records, nil
+}
+
+// isHeaderRow 判断一行是否为表头：至少有一个非空单元格，且所有非空单元格都是常见的表头名称
+func isHeaderRow(row []string) bool {
+	found := false
+	for _, cell := range row {
+		cell = strings.ToLower(strings.TrimSpace(cell))
+		if cell == "" {
+			continue
+		}
+		if !headerNames[cell] {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// headerIndex 返回表头中与 name 相同的列（不区分大小写），不存在时返回 -1
+func headerIndex(header []string, name string) int {
+	for i, cell := range header {
+		if strings.EqualFold(strings.TrimSpace(cell), strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// readJSONRecords 读取 JSON 数组，元素可以是字符串，或按 column 取字段的对象
+func readJSONRecords(filename string, opts InputOptions) ([]record, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败，需要字符串或对象数组: %v", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf(ErrEmptyFile)
+	}
+
+	var records []record
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			records = append(records, record{line: i + 1, text: v})
+		case map[string]interface{}:
+			if opts.Column != "" {
+				if s, ok := jsonString(v[opts.Column]); ok {
+					records = append(records, record{line: i + 1, text: s})
+				}
+				continue
+			}
+			// 未指定字段时按字段名顺序读取所有字符串值
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if s, ok := jsonString(v[key]); ok {
+					records = append(records, record{line: i + 1, text: s})
+				}
+			}
+		default:
+			if s, ok := jsonString(v); ok {
+				records = append(records, record{line: i + 1, text: s})
+			}
+		}
+	}
+	return records, nil
+}
+
+// jsonString 将 JSON 中的字符串和数字转换为文本
+func jsonString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package excel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestTableRecords(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        [][]string
+		column      string
+		allowLetter bool
+		want        []record
+	}{
+		{
+			name: "无表头",
+			rows: [][]string{{"example.com", "1.1.1.1"}, {"example.org"}},
+			want: []record{{1, "example.com"}, {1, "1.1.1.1"}, {2, "example.org"}},
+		},
+		{
+			name: "常见表头自动跳过",
+			rows: [][]string{{"Target", "IP", ""}, {"example.com", "1.1.1.1"}},
+			want: []record{{2, "example.com"}, {2, "1.1.1.1"}},
+		},
+		{
+			name: "中文表头自动跳过",
+			rows: [][]string{{"公司名称"}, {"示例科技有限公司"}},
+			want: []record{{2, "示例科技有限公司"}},
+		},
+		{
+			name: "部分单元格不是表头名称时不跳过",
+			rows: [][]string{{"domain", "example.com"}, {"example.org"}},
+			want: []record{{1, "domain"}, {1, "example.com"}, {2, "example.org"}},
+		},
+		{
+			name:   "按表头名称读取",
+			rows:   [][]string{{"name", "Host"}, {"a", "example.com"}, {"b"}},
+			column: "host",
+			want:   []record{{2, "example.com"}},
+		},
+		{
+			name:        "按列号读取不跳过第一行",
+			rows:        [][]string{{"a", "example.com"}, {"b", "example.org"}},
+			column:      "B",
+			allowLetter: true,
+			want:        []record{{1, "example.com"}, {2, "example.org"}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := tableRecords(tt.rows, tt.column, tt.allowLetter)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := tableRecords([][]string{{"a"}}, "B", false); err == nil {
+		t.Error("CSV 不支持列号，应返回错误")
+	}
+}
+
+func TestReadTargetsHeaderRow(t *testing.T) {
+	dir := t.TempDir()
+
+	csvFile := filepath.Join(dir, "targets.csv")
+	if err := os.WriteFile(csvFile, []byte("\ufefftarget,domain\n1.1.1.1,example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	xlsxFile := filepath.Join(dir, "targets.xlsx")
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	for cell, value := range map[string]string{"A1": "Target", "B1": "Domain", "A2": "1.1.1.1", "B2": "example.com"} {
+		if err := f.SetCellValue(sheet, cell, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(xlsxFile); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, file := range []string{csvFile, xlsxFile} {
+		targets, report, err := ReadTargets(file, "", InputOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if len(targets) != 2 || len(report.Rejected) != 0 {
+			t.Errorf("%s: targets = %v, rejected = %v, want 2 targets and no rejections", file, targets, report.Rejected)
+		}
+	}
+}
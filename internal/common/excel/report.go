@@ -0,0 +1,84 @@
+package excel
+
+import (
+	"bufio"
+	"cscan/internal/common/logger"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Rejection 一条被丢弃的输入
+type Rejection struct {
+	Line   int    // 行号，从 1 开始
+	Token  string // 原始内容
+	Reason string // 丢弃原因
+}
+
+// ParseReport 输入文件的解析报告，记录被丢弃的内容及原因
+type ParseReport struct {
+	File       string
+	Accepted   int // 有效条目数（去重后）
+	Duplicates int // 重复条目数
+	Rejected   []Rejection
+}
+
+// maxPrintedRejections 摘要中最多逐条输出的丢弃记录数
+const maxPrintedRejections = 20
+
+func (r *ParseReport) reject(line int, token, reason string) {
+	r.Rejected = append(r.Rejected, Rejection{Line: line, Token: token, Reason: reason})
+}
+
+// PrintSummary 输出解析摘要：按原因统计丢弃数量，并列出前若干条丢弃记录
+func (r *ParseReport) PrintSummary() {
+	logger.Printf("解析 %s: 有效 %d 条，重复 %d 条，丢弃 %d 条\n", r.File, r.Accepted, r.Duplicates, len(r.Rejected))
+	if len(r.Rejected) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, rej := range r.Rejected {
+		counts[rej.Reason]++
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	for _, reason := range reasons {
+		logger.Printf("  %-6d %s\n", counts[reason], reason)
+	}
+
+	for i, rej := range r.Rejected {
+		if i == maxPrintedRejections {
+			logger.Printf("  ... 另有 %d 条，可使用 -rejects 输出完整列表\n", len(r.Rejected)-maxPrintedRejections)
+			break
+		}
+		logger.Printf("  第 %d 行: %s (%s)\n", rej.Line, rej.Token, rej.Reason)
+	}
+}
+
+// WriteRejects 将丢弃记录写入文件，每行格式为 "行号<TAB>原始内容<TAB>原因"
+func (r *ParseReport) WriteRejects(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "# %s 中被丢弃的内容\n", r.File)
+	for _, rej := range r.Rejected {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", rej.Line, rej.Token, rej.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return file.Close()
+}
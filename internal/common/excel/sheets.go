@@ -0,0 +1,83 @@
+package excel
+
+import (
+	"cscan/internal/common/logger"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheet 一个工作表的表头和数据
+type Sheet struct {
+	Name         string
+	Headers      []string
+	Rows         [][]string
+	EmptyMessage string // 没有数据时在第二行合并显示的说明，为空时不显示
+}
+
+// WriteSheets 将多个工作表写为 xlsx 文件，每个工作表冻结首行、添加筛选并设置列宽
+func WriteSheets(w io.Writer, sheets []Sheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, sheet := range sheets {
+		// 第一个工作表沿用默认的 Sheet1 并改名，避免工作簿中残留空白工作表
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.Name); err != nil {
+				return fmt.Errorf("创建工作表 %s 失败: %v", sheet.Name, err)
+			}
+		} else if _, err := f.NewSheet(sheet.Name); err != nil {
+			return fmt.Errorf("创建工作表 %s 失败: %v", sheet.Name, err)
+		}
+		if err := writeSheet(f, sheet); err != nil {
+			return fmt.Errorf("写入工作表 %s 失败: %v", sheet.Name, err)
+		}
+	}
+	return f.Write(w)
+}
+
+// writeSheet 写入一个工作表的表头和数据
+func writeSheet(f *excelize.File, sheet Sheet) error {
+	if err := f.SetSheetRow(sheet.Name, "A1", &sheet.Headers); err != nil {
+		return err
+	}
+	for i, row := range sheet.Rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheet.Name, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(sheet.Headers))
+	if len(sheet.Rows) == 0 && sheet.EmptyMessage != "" {
+		if err := f.SetCellValue(sheet.Name, "A2", sheet.EmptyMessage); err != nil {
+			return err
+		}
+		if err := f.MergeCell(sheet.Name, "A2", lastCol+"2"); err != nil {
+			logger.Printf("合并单元格失败: %v\n", err)
+		}
+	}
+
+	lastRow := len(sheet.Rows) + 1
+	if lastRow < 2 {
+		lastRow = 2
+	}
+	if err := f.AutoFilter(sheet.Name, fmt.Sprintf("A1:%s%d", lastCol, lastRow), nil); err != nil {
+		logger.Printf("设置筛选失败: %v\n", err)
+	}
+	if err := f.SetPanes(sheet.Name, &excelize.Panes{
+		Freeze:      true,
+		Split:       false,
+		XSplit:      0,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		logger.Printf("冻结首行失败: %v\n", err)
+	}
+	if err := f.SetColWidth(sheet.Name, "A", lastCol, 20); err != nil {
+		logger.Printf("设置列宽失败: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package excel
+
+import (
+	"cscan/internal/common/logger"
+	"cscan/internal/common/model"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// resultSheet 结果所在的工作表
+const resultSheet = "Sheet1"
+
+// resultHeaders 结果表的表头
+var resultHeaders = []interface{}{"IP", "域名", "端口", "服务", "标题", "状态码", "ICP主体", "组织", "地理位置", "来源"}
+
+// StreamWriter 逐批写入 Excel 结果表，行数据由 excelize 的 StreamWriter 缓存到临时文件，
+// 内存占用与结果数量无关，Close 时生成完整的 xlsx 文件
+type StreamWriter struct {
+	w   io.Writer
+	f   *excelize.File
+	sw  *excelize.StreamWriter
+	row int // 已写入的行数，包括表头
+}
+
+// NewStreamWriter 创建 Excel 结果表，设置列宽、冻结首行并写入表头
+func NewStreamWriter(w io.Writer) (*StreamWriter, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(resultSheet)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("创建工作表失败: %v", err)
+	}
+
+	// 列宽和冻结首行必须在写入数据前设置
+	if err := sw.SetColWidth(1, len(resultHeaders), 20); err != nil {
+		logger.Printf("设置列宽失败: %v\n", err)
+	}
+	if err := sw.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		Split:       false,
+		XSplit:      0,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		logger.Printf("冻结首行失败: %v\n", err)
+	}
+
+	if err := sw.SetRow("A1", resultHeaders); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("写入表头失败: %v", err)
+	}
+	return &StreamWriter{w: w, f: f, sw: sw, row: 1}, nil
+}
+
+// Write 追加一批结果，调用方负责去重
+func (s *StreamWriter) Write(assets []model.Asset) error {
+	for _, asset := range assets {
+		s.row++
+		cell, _ := excelize.CoordinatesToCellName(1, s.row)
+		if err := s.sw.SetRow(cell, []interface{}{
+			asset.IP,
+			asset.Domain,
+			asset.Port,
+			asset.Service,
+			asset.Title,
+			asset.StatusCode,
+			asset.ICPOrg,
+			asset.Org,
+			asset.Location,
+			asset.Source,
+		}); err != nil {
+			return fmt.Errorf("写入第 %d 行失败: %v", s.row, err)
+		}
+	}
+	return nil
+}
+
+// Close 添加筛选并将 xlsx 文件写入 w
+func (s *StreamWriter) Close() error {
+	defer s.f.Close()
+
+	// 流式写入的工作表不支持 AutoFilter，使用带筛选按钮的表格代替
+	lastRow := s.row
+	if lastRow < 2 {
+		lastRow = 2 // 表格至少需要一行数据
+	}
+	lastCell, _ := excelize.CoordinatesToCellName(len(resultHeaders), lastRow)
+	showStripes := false
+	if err := s.sw.AddTable(&excelize.Table{
+		Range:          "A1:" + lastCell,
+		Name:           "Results",
+		ShowRowStripes: &showStripes,
+	}); err != nil {
+		return fmt.Errorf("设置筛选失败: %v", err)
+	}
+
+	if err := s.sw.Flush(); err != nil {
+		return fmt.Errorf("写入工作表失败: %v", err)
+	}
+	return s.f.Write(s.w)
+}
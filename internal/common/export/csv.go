@@ -0,0 +1,38 @@
+package export
+
+import (
+	"cscan/internal/common/model"
+	"encoding/csv"
+	"io"
+)
+
+// csvSink 流式输出 CSV，带 UTF-8 BOM 以便 Excel 正确识别中文
+type csvSink struct {
+	cw *csv.Writer
+}
+
+func newCSVSink(w io.Writer) (Sink, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers()); err != nil {
+		return nil, err
+	}
+	return &csvSink{cw: cw}, nil
+}
+
+func (s *csvSink) Write(assets []model.Asset) error {
+	for _, asset := range assets {
+		if err := s.cw.Write(row(asset)); err != nil {
+			return err
+		}
+	}
+	s.cw.Flush()
+	return s.cw.Error()
+}
+
+func (s *csvSink) Close() error {
+	s.cw.Flush()
+	return s.cw.Error()
+}
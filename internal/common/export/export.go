@@ -0,0 +1,253 @@
+package export
+
+import (
+	"cscan/internal/common/model"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Exporter 将完整的资产结果写为某种格式，用于无法流式写入的格式
+type Exporter interface {
+	// Write 将去重后的资产写入 w
+	Write(w io.Writer, assets []model.Asset) error
+}
+
+// Format 一种输出格式，Stream 和 Exporter 二选一
+type Format struct {
+	Name       string                        // 格式名称，用于 -format 参数
+	Extensions []string                      // 对应的文件扩展名，第一个为默认扩展名
+	Stream     func(io.Writer) (Sink, error) // 支持流式写入的格式，结果到达时即写入
+	Exporter   Exporter                      // 需要完整结果的格式，结束时一次性写入
+}
+
+// formats 所有支持的输出格式
+var formats = []Format{
+	{Name: "xlsx", Extensions: []string{".xlsx"}, Stream: newXLSXSink},
+	{Name: "json", Extensions: []string{".json"}, Exporter: jsonExporter{}},
+	{Name: "jsonl", Extensions: []string{".jsonl", ".ndjson"}, Stream: newJSONLSink},
+	{Name: "csv", Extensions: []string{".csv"}, Stream: newCSVSink},
+	{Name: "md", Extensions: []string{".md", ".markdown"}, Exporter: markdownExporter{}},
+	{Name: "html", Extensions: []string{".html", ".htm"}, Exporter: htmlExporter{}},
+	{Name: "hostport", Extensions: []string{".txt"}, Stream: newHostPortSink},
+}
+
+// 未指定格式时的默认值
+const (
+	DefaultFormat       = "xlsx"  // 输出到文件
+	DefaultStdoutFormat = "jsonl" // 输出到标准输出
+)
+
+// FormatNames 返回所有格式名称
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// LookupFormat 按名称查找格式
+func LookupFormat(name string) (Format, bool) {
+	for _, f := range formats {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// formatByExtension 按文件扩展名查找格式
+func formatByExtension(filename string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, f := range formats {
+		for _, e := range f.Extensions {
+			if ext == e {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// Output 一个输出目标，Path 为 "-" 时写入标准输出
+type Output struct {
+	Path   string
+	Format Format
+}
+
+// ParseOutputs 解析 -o 和 -format 参数，两者都可以用逗号分隔多个值
+//   - 未指定格式时按扩展名识别，无法识别的扩展名替换为 .xlsx，"-" 使用 jsonl
+//   - 一个输出路径配合多个格式时，按格式分别生成同名不同扩展名的文件
+//   - 多个输出路径配合格式时，两者数量必须相同并按顺序对应
+func ParseOutputs(paths, formatList string) ([]Output, error) {
+	outputNames := splitList(paths)
+	formatNames := splitList(formatList)
+	if len(outputNames) == 0 {
+		return nil, fmt.Errorf("未指定输出文件")
+	}
+
+	var formatsWanted []Format
+	for _, name := range formatNames {
+		f, ok := LookupFormat(name)
+		if !ok {
+			return nil, fmt.Errorf("不支持的输出格式: %s，可用格式: %s", name, strings.Join(FormatNames(), ", "))
+		}
+		formatsWanted = append(formatsWanted, f)
+	}
+
+	var outputs []Output
+	switch {
+	case len(formatsWanted) == 0:
+		for _, path := range outputNames {
+			outputs = append(outputs, outputFor(path))
+		}
+	case len(outputNames) == 1 && len(formatsWanted) == 1:
+		path := outputNames[0]
+		if path != "-" && filepath.Ext(path) == "" {
+			path += formatsWanted[0].Extensions[0]
+		}
+		outputs = append(outputs, Output{Path: path, Format: formatsWanted[0]})
+	case len(outputNames) == 1:
+		if outputNames[0] == "-" {
+			return nil, fmt.Errorf("标准输出只能使用一种格式")
+		}
+		base := trimExtension(outputNames[0])
+		for _, f := range formatsWanted {
+			outputs = append(outputs, Output{Path: base + f.Extensions[0], Format: f})
+		}
+	case len(outputNames) == len(formatsWanted):
+		for i, path := range outputNames {
+			outputs = append(outputs, Output{Path: path, Format: formatsWanted[i]})
+		}
+	default:
+		return nil, fmt.Errorf("输出文件数量 (%d) 与格式数量 (%d) 不一致", len(outputNames), len(formatsWanted))
+	}
+
+	// 同一路径或多个标准输出会互相覆盖
+	seen := make(map[string]bool)
+	for _, output := range outputs {
+		if seen[output.Path] {
+			return nil, fmt.Errorf("重复的输出文件: %s", output.Path)
+		}
+		seen[output.Path] = true
+	}
+	return outputs, nil
+}
+
+// outputFor 按扩展名确定输出格式
+func outputFor(path string) Output {
+	if path == "-" {
+		f, _ := LookupFormat(DefaultStdoutFormat)
+		return Output{Path: path, Format: f}
+	}
+	if f, ok := formatByExtension(path); ok {
+		return Output{Path: path, Format: f}
+	}
+	// 无法识别的扩展名沿用原来的行为，输出为 Excel
+	f, _ := LookupFormat(DefaultFormat)
+	return Output{Path: trimExtension(path) + f.Extensions[0], Format: f}
+}
+
+// trimExtension 去掉文件名的扩展名
+func trimExtension(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// Save 一次性将已获取的结果去重后写入所有输出，stdout 为 Path 是 "-" 时使用的输出
+// 某个输出失败时继续写入其他输出，返回所有错误
+func Save(results []model.Asset, outputs []Output, stdout io.Writer) error {
+	w, err := Open(outputs, stdout)
+	if err != nil {
+		return err
+	}
+	// 写入失败的输出会在 Close 时一并报告
+	w.Write(results)
+	return w.Close()
+}
+
+// displayName 返回用于日志显示的输出位置
+func displayName(path string) string {
+	if path == "-" {
+		return "标准输出"
+	}
+	return path
+}
+
+// splitList 拆分逗号分隔的参数，忽略空值
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// columns 表格类格式的列，与 Excel 输出保持一致
+var columns = []struct {
+	Header string
+	Value  func(model.Asset) string
+}{
+	{"IP", func(a model.Asset) string { return a.IP }},
+	{"域名", func(a model.Asset) string { return a.Domain }},
+	{"端口", func(a model.Asset) string { return a.Port }},
+	{"服务", func(a model.Asset) string { return a.Service }},
+	{"标题", func(a model.Asset) string { return a.Title }},
+	{"状态码", func(a model.Asset) string { return a.StatusCode }},
+	{"ICP主体", func(a model.Asset) string { return a.ICPOrg }},
+	{"组织", func(a model.Asset) string { return a.Org }},
+	{"地理位置", func(a model.Asset) string { return a.Location }},
+	{"来源", func(a model.Asset) string { return a.Source }},
+}
+
+// headers 返回表格列名
+func headers() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Header
+	}
+	return names
+}
+
+// row 返回资产在表格中的一行
+func row(asset model.Asset) []string {
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = c.Value(asset)
+	}
+	return values
+}
+
+// count 统计数量，用于汇总
+type count struct {
+	Name  string
+	Count int
+}
+
+// countBy 按 key 统计资产数量，按数量降序排列，key 为空时计入 empty
+func countBy(assets []model.Asset, key func(model.Asset) string, empty string) []count {
+	counts := make(map[string]int)
+	for _, asset := range assets {
+		k := key(asset)
+		if k == "" {
+			k = empty
+		}
+		counts[k]++
+	}
+
+	result := make([]count, 0, len(counts))
+	for name, n := range counts {
+		result = append(result, count{Name: name, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"cscan/internal/common/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		paths, formats string
+		want           []string // "路径 格式"
+	}{
+		{"results.xlsx", "", []string{"results.xlsx xlsx"}},
+		{"results", "", []string{"results.xlsx xlsx"}},
+		{"results.dat", "", []string{"results.xlsx xlsx"}},
+		{"out.csv, ,report.HTML", "", []string{"out.csv csv", "report.HTML html"}},
+		{"assets.txt,assets.ndjson", "", []string{"assets.txt hostport", "assets.ndjson jsonl"}},
+		{"-", "", []string{"- jsonl"}},
+		{"-", "hostport", []string{"- hostport"}},
+		{"out", "JSON", []string{"out.json json"}},
+		{"out.txt", "csv", []string{"out.txt csv"}},
+		{"report.xlsx", "xlsx,html,csv", []string{"report.xlsx xlsx", "report.html html", "report.csv csv"}},
+		{"a.json,-", "jsonl,hostport", []string{"a.json jsonl", "- hostport"}},
+	}
+	for _, tt := range tests {
+		outputs, err := ParseOutputs(tt.paths, tt.formats)
+		if err != nil {
+			t.Errorf("ParseOutputs(%q, %q) error: %v", tt.paths, tt.formats, err)
+			continue
+		}
+		var got []string
+		for _, o := range outputs {
+			got = append(got, o.Path+" "+o.Format.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseOutputs(%q, %q) = %v, want %v", tt.paths, tt.formats, got, tt.want)
+		}
+	}
+}
+
+func TestParseOutputsErrors(t *testing.T) {
+	tests := []struct {
+		paths, formats string
+		want           string // 错误信息应包含的内容
+	}{
+		{"", "", "未指定输出文件"},
+		{" , ", "", "未指定输出文件"},
+		{"out", "pdf", "不支持的输出格式: pdf"},
+		{"-", "json,csv", "标准输出只能使用一种格式"},
+		{"a,b", "json,csv,md", "数量"},
+		{"a.xlsx,a.xlsx", "", "重复的输出文件: a.xlsx"},
+		{"-,-", "", "重复的输出文件: -"},
+		{"a,a.xlsx", "", "重复的输出文件: a.xlsx"},
+	}
+	for _, tt := range tests {
+		_, err := ParseOutputs(tt.paths, tt.formats)
+		if err == nil {
+			t.Errorf("ParseOutputs(%q, %q) 应返回错误", tt.paths, tt.formats)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseOutputs(%q, %q) error = %q, want containing %q", tt.paths, tt.formats, err, tt.want)
+		}
+	}
+}
+
+func TestSaveStdout(t *testing.T) {
+	outputs, err := ParseOutputs("-", "hostport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []model.Asset{
+		{IP: "1.1.1.1", Port: "443", Source: "FOFA"},
+		{IP: "1.1.1.1", Port: "443", Source: "Quake"},
+		{Domain: "example.com", Port: "80"},
+	}
+
+	var buf bytes.Buffer
+	if err := Save(results, outputs, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "1.1.1.1:443\nexample.com:80\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestTableFormatsIncludeOrg(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "results")
+	outputs, err := ParseOutputs(base, "xlsx,csv,md,html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []model.Asset{{IP: "1.1.1.1", Port: "443", ICPOrg: "示例科技有限公司", Org: "Cloudflare, Inc.", Source: "Shodan"}}
+	if err := Save(results, outputs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ext := range []string{".csv", ".md", ".html"} {
+		data, err := os.ReadFile(base + ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "组织") || !strings.Contains(string(data), "Cloudflare, Inc.") {
+			t.Errorf("%s 缺少组织列", ext)
+		}
+	}
+
+	f, err := excelize.OpenFile(base + ".xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) < 2 || !reflect.DeepEqual(rows[0], headers()) {
+		t.Fatalf("xlsx rows = %v, want headers %v", rows, headers())
+	}
+	for i, header := range rows[0] {
+		if header == "组织" && rows[1][i] != "Cloudflare, Inc." {
+			t.Errorf("xlsx 组织 = %q", rows[1][i])
+		}
+	}
+}
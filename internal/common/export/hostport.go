@@ -0,0 +1,52 @@
+package export
+
+import (
+	"bufio"
+	"cscan/internal/common/model"
+	"fmt"
+	"io"
+	"net"
+)
+
+// hostPortSink 每行输出一个 host:port，便于交给 httpx、nuclei 等工具，每批结果写入后立即刷新
+type hostPortSink struct {
+	bw   *bufio.Writer
+	seen map[string]bool // 不同引擎返回的同一端口只输出一次
+}
+
+func newHostPortSink(w io.Writer) (Sink, error) {
+	return &hostPortSink{bw: bufio.NewWriter(w), seen: make(map[string]bool)}, nil
+}
+
+func (s *hostPortSink) Write(assets []model.Asset) error {
+	for _, asset := range assets {
+		line := hostPort(asset)
+		if line == "" || s.seen[line] {
+			continue
+		}
+		s.seen[line] = true
+		if _, err := fmt.Fprintln(s.bw, line); err != nil {
+			return err
+		}
+	}
+	return s.bw.Flush()
+}
+
+func (s *hostPortSink) Close() error {
+	return s.bw.Flush()
+}
+
+// hostPort 返回资产的 host:port，优先使用域名以保留虚拟主机信息，没有端口时只返回主机
+func hostPort(asset model.Asset) string {
+	host := asset.Domain
+	if host == "" {
+		host = asset.IP
+	}
+	if host == "" {
+		return ""
+	}
+	if asset.Port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, asset.Port)
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"cscan/internal/common/model"
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlExporter 输出自包含的 HTML 报告，包含按引擎和端口的汇总及完整资产表格
+type htmlExporter struct{}
+
+// maxPortRows 端口汇总最多显示的行数
+const maxPortRows = 50
+
+// htmlReport 报告模板的数据
+type htmlReport struct {
+	GeneratedAt string
+	Total       int
+	Hosts       int
+	Engines     []count
+	Ports       []count
+	MorePorts   int // 未显示的端口数量
+	Headers     []string
+	Rows        [][]string
+}
+
+func (htmlExporter) Write(w io.Writer, assets []model.Asset) error {
+	report := htmlReport{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Total:       len(assets),
+		Engines:     countBy(assets, func(a model.Asset) string { return a.Source }, "未知"),
+		Ports:       countBy(assets, func(a model.Asset) string { return a.Port }, "无端口"),
+		Headers:     headers(),
+	}
+	report.Hosts = len(countBy(assets, func(a model.Asset) string {
+		if a.IP != "" {
+			return a.IP
+		}
+		return a.Domain
+	}, ""))
+	if len(report.Ports) > maxPortRows {
+		report.MorePorts = len(report.Ports) - maxPortRows
+		report.Ports = report.Ports[:maxPortRows]
+	}
+	for _, asset := range assets {
+		report.Rows = append(report.Rows, row(asset))
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>CScan 资产报告</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 22px; margin-bottom: 4px; }
+h2 { font-size: 17px; margin-top: 28px; }
+.meta { color: #666; font-size: 13px; }
+.cards { display: flex; gap: 12px; margin: 16px 0; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: 10px 16px; min-width: 120px; }
+.card b { display: block; font-size: 22px; }
+.summary { display: flex; gap: 32px; flex-wrap: wrap; align-items: flex-start; }
+table { border-collapse: collapse; font-size: 13px; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f5f7; position: sticky; top: 0; }
+tr:nth-child(even) td { background: #fafafa; }
+td.num { text-align: right; }
+.assets td { max-width: 360px; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>CScan 资产报告</h1>
+<div class="meta">生成时间: {{.GeneratedAt}}</div>
+<div class="cards">
+<div class="card"><b>{{.Total}}</b>资产</div>
+<div class="card"><b>{{.Hosts}}</b>主机</div>
+<div class="card"><b>{{len .Engines}}</b>来源引擎</div>
+</div>
+<div class="summary">
+<div>
+<h2>按引擎统计</h2>
+<table>
+<tr><th>引擎</th><th>资产数</th></tr>
+{{range .Engines}}<tr><td>{{.Name}}</td><td class="num">{{.Count}}</td></tr>
+{{end}}</table>
+</div>
+<div>
+<h2>按端口统计</h2>
+<table>
+<tr><th>端口</th><th>资产数</th></tr>
+{{range .Ports}}<tr><td>{{.Name}}</td><td class="num">{{.Count}}</td></tr>
+{{end}}</table>
+{{if .MorePorts}}<div class="meta">另有 {{.MorePorts}} 个端口未显示</div>{{end}}
+</div>
+</div>
+<h2>资产列表</h2>
+<table class="assets">
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
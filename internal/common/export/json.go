@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"cscan/internal/common/model"
+	"encoding/json"
+	"io"
+)
+
+// jsonExporter 输出 JSON 数组
+type jsonExporter struct{}
+
+func (jsonExporter) Write(w io.Writer, assets []model.Asset) error {
+	if assets == nil {
+		assets = []model.Asset{} // 没有结果时输出 [] 而不是 null
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(assets)
+}
+
+// jsonlSink 流式输出 JSON Lines，每行一个资产，每批结果写入后立即刷新，中断时已写入的行不会丢失
+type jsonlSink struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLSink(w io.Writer) (Sink, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &jsonlSink{bw: bw, enc: enc}, nil
+}
+
+func (s *jsonlSink) Write(assets []model.Asset) error {
+	for _, asset := range assets {
+		if err := s.enc.Encode(asset); err != nil {
+			return err
+		}
+	}
+	return s.bw.Flush()
+}
+
+func (s *jsonlSink) Close() error {
+	return s.bw.Flush()
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"bufio"
+	"cscan/internal/common/model"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownExporter 输出 Markdown 表格
+type markdownExporter struct{}
+
+func (markdownExporter) Write(w io.Writer, assets []model.Asset) error {
+	bw := bufio.NewWriter(w)
+	writeRow := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = markdownEscaper.Replace(cell)
+		}
+		fmt.Fprintf(bw, "| %s |\n", strings.Join(escaped, " | "))
+	}
+
+	writeRow(headers())
+	fmt.Fprintf(bw, "|%s\n", strings.Repeat(" --- |", len(columns)))
+	for _, asset := range assets {
+		writeRow(row(asset))
+	}
+	return bw.Flush()
+}
+
+// markdownEscaper 转义单元格中会破坏表格的字符
+var markdownEscaper = strings.NewReplacer("|", "\\|", "<", "&lt;", "\r\n", " ", "\n", " ", "\r", " ")
@@ -0,0 +1,165 @@
+package export
+
+import (
+	"cscan/internal/common/logger"
+	"cscan/internal/common/model"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink 逐批接收结果并增量写入输出，Close 时完成写入
+type Sink interface {
+	Write(assets []model.Asset) error
+	Close() error
+}
+
+// bufferSink 用于需要完整结果才能生成的格式（如带汇总的 HTML 报告），Close 时一次性导出
+type bufferSink struct {
+	w        io.Writer
+	exporter Exporter
+	assets   []model.Asset
+}
+
+func (s *bufferSink) Write(assets []model.Asset) error {
+	s.assets = append(s.assets, assets...)
+	return nil
+}
+
+func (s *bufferSink) Close() error {
+	return s.exporter.Write(s.w, s.assets)
+}
+
+// output 已打开的输出
+type output struct {
+	Output
+	sink Sink
+	file *os.File // 标准输出时为 nil
+	err  error    // 写入失败后不再写入该输出
+}
+
+// Writer 将结果去重后写入所有输出，可被多个 goroutine 同时调用
+// 去重只保存已出现资产的 Key，支持流式写入的格式不会在内存中保留结果
+type Writer struct {
+	mu      sync.Mutex
+	outputs []*output
+	keys    model.KeySet
+	total   int
+	unique  int
+}
+
+// Open 打开所有输出并写入表头，任一输出无法创建时关闭已打开的输出并返回错误
+func Open(outputs []Output, stdout io.Writer) (*Writer, error) {
+	w := &Writer{keys: make(model.KeySet)}
+	for _, o := range outputs {
+		opened, err := openOutput(o, stdout)
+		if err != nil {
+			w.abort()
+			return nil, fmt.Errorf("打开 %s 失败: %v", displayName(o.Path), err)
+		}
+		w.outputs = append(w.outputs, opened)
+	}
+	return w, nil
+}
+
+// openOutput 创建输出文件和对应格式的 Sink
+func openOutput(o Output, stdout io.Writer) (*output, error) {
+	opened := &output{Output: o}
+	var dst io.Writer = stdout
+	if o.Path != "-" {
+		file, err := os.Create(o.Path)
+		if err != nil {
+			return nil, err
+		}
+		opened.file = file
+		dst = file
+	}
+
+	var err error
+	if o.Format.Stream != nil {
+		opened.sink, err = o.Format.Stream(dst)
+	} else {
+		opened.sink = &bufferSink{w: dst, exporter: o.Format.Exporter}
+	}
+	if err != nil {
+		if opened.file != nil {
+			opened.file.Close()
+		}
+		return nil, err
+	}
+	return opened, nil
+}
+
+// abort 关闭已打开的文件，用于打开失败时清理
+func (w *Writer) abort() {
+	for _, o := range w.outputs {
+		if o.file != nil {
+			o.file.Close()
+		}
+	}
+}
+
+// Write 去重后将结果写入所有输出，某个输出失败时继续写入其他输出
+func (w *Writer) Write(assets []model.Asset) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.total += len(assets)
+	var unique []model.Asset
+	for _, asset := range assets {
+		if w.keys.Add(asset) {
+			unique = append(unique, asset)
+		}
+	}
+	w.unique += len(unique)
+	if len(unique) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, o := range w.outputs {
+		if o.err != nil {
+			continue
+		}
+		if err := o.sink.Write(unique); err != nil {
+			o.err = err
+			errs = append(errs, fmt.Errorf("写入 %s 失败: %v", displayName(o.Path), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Total 返回已接收的结果数量（去重前）
+func (w *Writer) Total() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.total
+}
+
+// Close 完成所有输出的写入并关闭文件，返回所有失败的输出
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	logger.Printf("去重前: %d 条记录, 去重后: %d 条记录\n", w.total, w.unique)
+	var errs []error
+	for _, o := range w.outputs {
+		err := o.err
+		if closeErr := o.sink.Close(); err == nil {
+			err = closeErr
+		}
+		if o.file != nil {
+			if closeErr := o.file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("保存 %s 失败: %v", displayName(o.Path), err))
+			continue
+		}
+		logger.Printf("结果已保存到 %s (%s)\n", displayName(o.Path), o.Format.Name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,11 @@
+package export
+
+import (
+	"cscan/internal/common/excel"
+	"io"
+)
+
+// newXLSXSink 流式写入带筛选和冻结首行的 Excel 文件
+func newXLSXSink(w io.Writer) (Sink, error) {
+	return excel.NewStreamWriter(w)
+}
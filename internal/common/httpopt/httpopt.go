@@ -0,0 +1,62 @@
+package httpopt
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client 各扫描器共用的 HTTP 配置，嵌入到扫描器结构体中使用
+type Client struct {
+	BaseURL    string       // API 地址，不含末尾的 /
+	HTTPClient *http.Client // 发送请求使用的 HTTP 客户端
+}
+
+// Option 扫描器的 HTTP 配置项
+type Option func(*Client)
+
+// WithBaseURL 设置自定义 API 地址（代理、私有化部署或本地测试）
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient 使用共享的 HTTP 客户端
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		if client != nil {
+			c.HTTPClient = client
+		}
+	}
+}
+
+// WithTransport 设置 HTTP 传输层
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		client := *c.HTTPClient
+		client.Transport = transport
+		c.HTTPClient = &client
+	}
+}
+
+// WithProxy 通过代理发送请求，支持 http、https 和 socks5 代理
+func WithProxy(proxy *url.URL) Option {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxy)
+		WithTransport(transport)(c)
+	}
+}
+
+// New 使用默认 API 地址创建配置并应用配置项
+func New(defaultBaseURL string, opts ...Option) Client {
+	c := Client{
+		BaseURL:    defaultBaseURL,
+		HTTPClient: &http.Client{},
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
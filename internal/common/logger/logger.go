@@ -2,9 +2,47 @@ package logger
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sync"
 	"time"
 )
 
+// 进度和日志信息的输出位置，默认为标准输出
+var (
+	output io.Writer = os.Stdout
+	mu     sync.Mutex
+)
+
+// SetOutput 设置进度和日志信息的输出位置
+// 结果写入标准输出时应设置为标准错误，避免日志混入管道中的结果
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+}
+
+// Printf 输出进度信息
+func Printf(format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintf(output, format, args...)
+}
+
+// Println 输出一行进度信息
+func Println(args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintln(output, args...)
+}
+
+// Print 输出进度信息，不追加换行
+func Print(args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprint(output, args...)
+}
+
 func Info(format string, args ...interface{}) {
 	log("[INFO] "+format, args...)
 }
@@ -15,5 +53,5 @@ func Error(format string, args ...interface{}) {
 
 func log(format string, args ...interface{}) {
 	prefix := time.Now().Format("2006-01-02 15:04:05")
-	fmt.Printf(prefix+" "+format+"\n", args...)
+	Printf(prefix+" "+format+"\n", args...)
 }
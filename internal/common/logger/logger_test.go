@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+
+	Printf("第 %d 页\n", 1)
+	Println("完成")
+	Print("结束")
+	if got, want := buf.String(), "第 1 页\n完成\n结束"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
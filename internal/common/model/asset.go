@@ -1,28 +1,105 @@
 package model
 
+import "strings"
+
 // Asset 表示一个资产记录
 type Asset struct {
-	IP           string
-	Domain       string
-	Port         string
-	Service      string
-	Title        string
-	StatusCode   string
-	ICPOrg       string
-	Location     string
-	Source       string
-	UpdatedAt    string
-	Registrar    string
-	RegisterTime string
-	ExpireTime   string
-	Status       string
-	Package      string
-	Version      string
-	Platform     string
-	Size         string
-	Developer    string
-	Category     string
-	Language     string
-	Department   string
-	Position     string
+	IP           string `json:"ip,omitempty"`
+	Domain       string `json:"domain,omitempty"`
+	Port         string `json:"port,omitempty"`
+	Service      string `json:"service,omitempty"`
+	Title        string `json:"title,omitempty"`
+	StatusCode   string `json:"status_code,omitempty"`
+	ICPOrg       string `json:"icp_org,omitempty"`
+	Org          string `json:"org,omitempty"` // IP 所属的网络运营组织（如 Shodan 的 org），不是 ICP 备案主体
+	Location     string `json:"location,omitempty"`
+	Source       string `json:"source,omitempty"`
+	UpdatedAt    string `json:"updated_at,omitempty"`
+	Registrar    string `json:"registrar,omitempty"`
+	RegisterTime string `json:"register_time,omitempty"`
+	ExpireTime   string `json:"expire_time,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Package      string `json:"package,omitempty"`
+	Version      string `json:"version,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+	Size         string `json:"size,omitempty"`
+	Developer    string `json:"developer,omitempty"`
+	Category     string `json:"category,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Department   string `json:"department,omitempty"`
+	Position     string `json:"position,omitempty"`
+	Type         string `json:"type,omitempty"` // 公司情报的数据类型（如 0.zone 的 site、apk、member），空间测绘结果为空
+	Name         string `json:"name,omitempty"`
+	Email        string `json:"email,omitempty"`
+	URL          string `json:"url,omitempty"`
+}
+
+// Key 生成资产的唯一标识，用于去重
+func (a Asset) Key() string {
+	// 不同类型的数据（如 site 和 domain 中的同一域名）分别保留
+	if a.Type != "" {
+		return a.Type + "|" + a.key()
+	}
+	return a.key()
+}
+
+func (a Asset) key() string {
+	// 如果有IP和端口，使用"IP:端口"作为key
+	if a.IP != "" && a.Port != "" {
+		return a.IP + ":" + a.Port
+	}
+	// 如果有域名，使用域名作为key
+	if a.Domain != "" {
+		return a.Domain
+	}
+	// 如果都没有，使用所有非空字段组合
+	parts := []string{
+		a.IP,
+		a.Domain,
+		a.Port,
+		a.Service,
+		a.Title,
+		a.StatusCode,
+		a.ICPOrg,
+		a.Location,
+		a.Name,
+		a.Email,
+		a.URL,
+		a.Package,
+		a.Version,
+		a.Platform,
+		a.Position,
+		a.Department,
+	}
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "|")
+}
+
+// KeySet 记录已出现的资产 Key，用于流式去重，只保存 Key 而不保存资产本身
+type KeySet map[string]struct{}
+
+// Add 添加资产的 Key，返回该资产是否第一次出现
+func (s KeySet) Add(asset Asset) bool {
+	key := asset.Key()
+	if _, ok := s[key]; ok {
+		return false
+	}
+	s[key] = struct{}{}
+	return true
+}
+
+// JoinLocation 用空格连接国家、省份、城市等地理位置信息，忽略空值
+func JoinLocation(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
 }
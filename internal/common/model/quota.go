@@ -0,0 +1,9 @@
+package model
+
+// Quota 表示账户的剩余额度
+type Quota struct {
+	Remaining int    // 剩余可用额度
+	Unit      string // 额度单位，如 "次"、"积分"
+	PerResult bool   // 是否按返回的结果条数计费，否则按请求次数计费
+	Detail    string // 额外说明
+}
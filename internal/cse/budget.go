@@ -0,0 +1,77 @@
+package cse
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage 单个引擎本次运行的额度使用情况
+type Usage struct {
+	Name        string
+	Requests    int // 实际发出的 API 请求数
+	Results     int // 接口返回的结果数
+	CacheHits   int // 命中缓存或断点记录的页数
+	MaxRequests int // 请求数上限，0 表示不限制
+	MaxResults  int // 结果数上限，0 表示不限制
+	Exhausted   bool
+}
+
+// budget 引擎额度管理
+type budget struct {
+	usage Usage
+	mu    sync.Mutex
+}
+
+func newBudget(name string) *budget {
+	return &budget{usage: Usage{Name: name}}
+}
+
+// acquire 预占一次请求额度，额度用尽时返回 false
+func (b *budget) acquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	u := &b.usage
+	if (u.MaxRequests > 0 && u.Requests >= u.MaxRequests) ||
+		(u.MaxResults > 0 && u.Results >= u.MaxResults) {
+		u.Exhausted = true
+		return false
+	}
+	u.Requests++
+	return true
+}
+
+// addResults 累计返回的结果数
+func (b *budget) addResults(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage.Results += n
+}
+
+// addCacheHit 累计未消耗额度的页数
+func (b *budget) addCacheHit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage.CacheHits++
+}
+
+// snapshot 返回当前使用情况
+func (b *budget) snapshot() Usage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usage
+}
+
+// acquireKey 上下文中保存额度预占函数的键
+type acquireKey struct{}
+
+// withAcquire 在上下文中附带额度预占函数，包装器在一次调用中发出额外的请求时（如账号池切换账号重试）使用
+func withAcquire(ctx context.Context, acquire func() bool) context.Context {
+	return context.WithValue(ctx, acquireKey{}, acquire)
+}
+
+// acquireRequest 为一次额外的请求预占额度，上下文中没有预占函数时不限制
+func acquireRequest(ctx context.Context) bool {
+	acquire, ok := ctx.Value(acquireKey{}).(func() bool)
+	return !ok || acquire()
+}
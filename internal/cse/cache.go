@@ -0,0 +1,318 @@
+package cse
+
+import (
+	"context"
+	"crypto/sha256"
+	"cscan/internal/common/logger"
+	"cscan/internal/common/model"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 缓存默认配置
+const (
+	DefaultCacheDir     = ".cscan_cache"
+	DefaultCacheTTL     = 24 * time.Hour
+	DefaultCacheMaxSize = 512 << 20 // 缓存目录的总大小上限，512 MB
+)
+
+// Cache 本地响应缓存，按 (扫描器, 查询, 页, 每页数量) 存储接口返回的原始响应体
+// 命中时由扫描器重新解析，字段映射的修复对已缓存的响应同样生效
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	refresh bool
+}
+
+// cacheEntry 缓存文件内容
+type cacheEntry struct {
+	Scanner string    `json:"scanner"`
+	Query   string    `json:"query"`
+	Page    int       `json:"page,omitempty"`
+	Cursor  string    `json:"cursor,omitempty"`
+	Size    int       `json:"size"`
+	Time    time.Time `json:"time"`
+	Body    []byte    `json:"body"`
+}
+
+// NewCache 创建本地缓存
+// maxSize 为缓存目录的总大小上限（字节），refresh 为 true 时忽略已有缓存，重新请求并覆盖写入
+func NewCache(dir string, ttl time.Duration, maxSize int64, refresh bool) (*Cache, error) {
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultCacheMaxSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+	return &Cache{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: maxSize,
+		refresh: refresh,
+	}, nil
+}
+
+// Get 读取未过期的响应体
+func (c *Cache) Get(scanner, query string, page, size int) ([]byte, bool) {
+	return c.get(c.path(scanner, query, fmt.Sprintf("page=%d", page), size))
+}
+
+// Put 写入一页的响应体
+func (c *Cache) Put(scanner, query string, page, size int, body []byte) error {
+	return c.put(c.path(scanner, query, fmt.Sprintf("page=%d", page), size), cacheEntry{
+		Scanner: scanner,
+		Query:   query,
+		Page:    page,
+		Size:    size,
+		Body:    body,
+	})
+}
+
+// GetCursor 读取游标分页的响应体
+func (c *Cache) GetCursor(scanner, query, cursor string, size int) ([]byte, bool) {
+	return c.get(c.path(scanner, query, "cursor="+cursor, size))
+}
+
+// PutCursor 写入游标分页的响应体
+func (c *Cache) PutCursor(scanner, query, cursor string, size int, body []byte) error {
+	return c.put(c.path(scanner, query, "cursor="+cursor, size), cacheEntry{
+		Scanner: scanner,
+		Query:   query,
+		Cursor:  cursor,
+		Size:    size,
+		Body:    body,
+	})
+}
+
+func (c *Cache) get(path string) ([]byte, bool) {
+	if c.refresh {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if time.Since(entry.Time) > c.ttl || len(entry.Body) == 0 {
+		return nil, false
+	}
+	return entry.Body, true
+}
+
+func (c *Cache) put(path string, entry cacheEntry) error {
+	entry.Time = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化缓存失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免并发读到写了一半的缓存
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入缓存失败: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *Cache) path(scanner, query, position string, size int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", scanner, query, position, size)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Sweep 清理缓存目录：删除过期的缓存和残留的临时文件，
+// 总大小仍超过上限时从最早写入的缓存开始删除，返回删除的文件数
+func (c *Cache) Sweep() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, fmt.Errorf("读取缓存目录失败: %v", err)
+	}
+
+	type cacheFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var (
+		files   []cacheFile
+		total   int64
+		removed int
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.tmp")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, name)
+		// 缓存文件写入后不再修改，修改时间即写入时间；临时文件超过一小时视为写入中断的残留
+		age := time.Since(info.ModTime())
+		expired := age > c.ttl
+		if strings.HasSuffix(name, ".tmp") {
+			expired = age > time.Hour
+		}
+		if expired {
+			if os.Remove(path) == nil {
+				removed++
+			}
+			continue
+		}
+		if strings.HasSuffix(name, ".json") {
+			files = append(files, cacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+			total += info.Size()
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// CachedScanner 为任意扫描器增加本地缓存
+// 只有实现了 RawScanner 的扫描器才会缓存，其他扫描器直接转发请求
+type CachedScanner struct {
+	scanner Scanner
+	cache   *Cache
+}
+
+// NewCachedScanner 创建带缓存的扫描器
+func NewCachedScanner(scanner Scanner, cache *Cache) *CachedScanner {
+	return &CachedScanner{
+		scanner: scanner,
+		cache:   cache,
+	}
+}
+
+// Name 返回被包装扫描器的名称
+func (s *CachedScanner) Name() string {
+	return s.scanner.Name()
+}
+
+// Search 优先返回缓存结果，未命中时请求接口并缓存原始响应
+func (s *CachedScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	if assets, ok := s.lookup(query, page, size); ok {
+		return assets, nil
+	}
+	return s.fetch(ctx, query, page, size)
+}
+
+// fetch 跳过缓存查询，直接请求接口并缓存原始响应
+// 供已查询过缓存的调用方（如 SearchEngine）使用，避免未命中时重复读取缓存文件
+func (s *CachedScanner) fetch(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	raw, ok := s.scanner.(RawScanner)
+	if !ok {
+		return s.scanner.Search(ctx, query, page, size)
+	}
+	body, err := raw.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := raw.Parse(body)
+	if err != nil {
+		// 无法解析的响应不写入缓存
+		return nil, err
+	}
+	if err := s.cache.Put(s.Name(), query, page, size, body); err != nil {
+		logger.Printf("[%s] %v\n", s.Name(), err)
+	}
+	return assets, nil
+}
+
+// SearchCursor 按游标搜索，优先返回缓存结果
+func (s *CachedScanner) SearchCursor(ctx context.Context, query, cursor string, size int) ([]model.Asset, string, error) {
+	if assets, next, ok := s.lookupCursor(query, cursor, size); ok {
+		return assets, next, nil
+	}
+	return s.fetchCursor(ctx, query, cursor, size)
+}
+
+// fetchCursor 跳过缓存查询，按游标请求接口并缓存原始响应
+func (s *CachedScanner) fetchCursor(ctx context.Context, query, cursor string, size int) ([]model.Asset, string, error) {
+	raw, ok := s.scanner.(RawScanner)
+	if !ok {
+		cs, ok := s.scanner.(CursorScanner)
+		if !ok {
+			return nil, "", fmt.Errorf("%s 不支持游标分页", s.Name())
+		}
+		return cs.SearchCursor(ctx, query, cursor, size)
+	}
+	body, err := raw.Fetch(ctx, query, 0, cursor, size)
+	if err != nil {
+		return nil, "", err
+	}
+	assets, next, err := raw.Parse(body)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.cache.PutCursor(s.Name(), query, cursor, size, body); err != nil {
+		logger.Printf("[%s] %v\n", s.Name(), err)
+	}
+	return assets, next, nil
+}
+
+// Unwrap 返回被包装的扫描器
+func (s *CachedScanner) Unwrap() Scanner {
+	return s.scanner
+}
+
+// lookup 查询缓存并重新解析，命中时无需等待速率限制
+func (s *CachedScanner) lookup(query string, page, size int) ([]model.Asset, bool) {
+	raw, ok := s.scanner.(RawScanner)
+	if !ok {
+		return nil, false
+	}
+	body, ok := s.cache.Get(s.Name(), query, page, size)
+	if !ok {
+		return nil, false
+	}
+	assets, _, err := raw.Parse(body)
+	if err != nil {
+		return nil, false
+	}
+	return assets, true
+}
+
+// lookupCursor 按游标查询缓存并重新解析
+func (s *CachedScanner) lookupCursor(query, cursor string, size int) ([]model.Asset, string, bool) {
+	raw, ok := s.scanner.(RawScanner)
+	if !ok {
+		return nil, "", false
+	}
+	body, ok := s.cache.GetCursor(s.Name(), query, cursor, size)
+	if !ok {
+		return nil, "", false
+	}
+	assets, next, err := raw.Parse(body)
+	if err != nil {
+		return nil, "", false
+	}
+	return assets, next, true
+}
@@ -0,0 +1,135 @@
+package cse
+
+import (
+	"context"
+	"cscan/internal/common/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rawScanner 返回固定响应体的测试扫描器，prefix 模拟解析逻辑的变化
+type rawScanner struct {
+	fetches int
+	prefix  string
+}
+
+func (s *rawScanner) Name() string { return "Raw" }
+
+func (s *rawScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	body, err := s.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := s.Parse(body)
+	return assets, err
+}
+
+func (s *rawScanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
+	s.fetches++
+	return []byte("1.1.1.1,2.2.2.2"), nil
+}
+
+func (s *rawScanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var assets []model.Asset
+	for _, ip := range strings.Split(string(body), ",") {
+		assets = append(assets, model.Asset{IP: ip, Source: s.prefix + "Raw"})
+	}
+	return assets, "", nil
+}
+
+func TestCachedScannerReparsesRawBody(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), time.Hour, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &rawScanner{}
+	scanner := NewCachedScanner(inner, cache)
+
+	if _, err := scanner.Search(context.Background(), "q", 1, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// 解析逻辑变化后，缓存命中返回按新逻辑解析的结果
+	inner.prefix = "New"
+	assets, ok := scanner.lookup("q", 1, 10)
+	if !ok {
+		t.Fatal("lookup() 未命中缓存")
+	}
+	if inner.fetches != 1 {
+		t.Errorf("fetches = %d, want 1", inner.fetches)
+	}
+	if len(assets) != 2 || assets[0].Source != "NewRaw" {
+		t.Errorf("lookup() = %+v, want 2 assets from NewRaw", assets)
+	}
+
+	// 页码或每页数量不同时不命中
+	if _, ok := scanner.lookup("q", 2, 10); ok {
+		t.Error("不同页码命中了缓存")
+	}
+	if _, ok := scanner.lookup("q", 1, 20); ok {
+		t.Error("不同每页数量命中了缓存")
+	}
+}
+
+func TestCacheSweep(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(dir, time.Hour, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put("Raw", "old", 1, 10, []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put("Raw", "new", 1, 10, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	expired := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(cache.path("Raw", "old", "page=1", 10), expired, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	// 过期的文件被删除，剩余文件超过 1 字节的上限也被删除
+	removed, err := cache.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("Sweep() removed %d files, want 2", removed)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 0 {
+		t.Errorf("remaining files = %v, want none", files)
+	}
+}
+
+func TestSearchEngineUsesCache(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), time.Hour, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &rawScanner{}
+	scanner := NewCachedScanner(inner, cache)
+	targets := []Target{{Value: "q", Type: "query"}}
+
+	// 首次搜索未命中，由 fetch 直接请求并写入缓存，之后的搜索命中缓存
+	for run := 1; run <= 2; run++ {
+		results, err := withoutDelay(NewSearchEngine(scanner)).SearchTargets(context.Background(), targets, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 2 || inner.fetches != 1 {
+			t.Errorf("run %d: %d results, fetches = %d, want 2 results and 1 fetch", run, len(results), inner.fetches)
+		}
+	}
+
+	// fetch 不查询缓存，总是请求接口
+	if _, err := scanner.fetch(context.Background(), "q", 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if inner.fetches != 2 {
+		t.Errorf("fetches = %d after fetch(), want 2", inner.fetches)
+	}
+}
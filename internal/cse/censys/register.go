@@ -0,0 +1,63 @@
+package censys
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+)
+
+func init() {
+	cse.Register(cse.Engine{
+		Name:        "censys",
+		Description: "Censys 引擎，按游标翻页",
+		ConfigKeys:  []string{"censys_api_id", "censys_api_secret", "censys_accounts"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.CensysKeys()) > 0
+		},
+		New: func(cfg *config.Config) []cse.Scanner {
+			return []cse.Scanner{newFromConfig(cfg)}
+		},
+		Queries: map[string]cse.QueryBuilder{
+			"Censys": buildQuery,
+		},
+	})
+}
+
+// buildQuery 将目标转换为 Censys 查询语句
+// Censys 原生支持网段和 IP 范围查询
+func buildQuery(target cse.Target) string {
+	switch target.Type {
+	case "ip":
+		return fmt.Sprintf(`ip: %s`, target.Value)
+	case "ipv6", "cidr":
+		return fmt.Sprintf(`ip: "%s"`, target.Value)
+	case "range":
+		start, end, err := cse.ParseRange(target.Value)
+		if err != nil {
+			return fmt.Sprintf(`ip: "%s"`, target.Value)
+		}
+		return fmt.Sprintf(`ip: [%s to %s]`, start, end)
+	case "asn":
+		return fmt.Sprintf(`autonomous_system.asn: %s`, target.Value)
+	case "host":
+		return fmt.Sprintf(`dns.names: "%s"`, target.Value)
+	}
+	return fmt.Sprintf(`dns.names: %s OR dns.names: *.%s`, target.Value, target.Value)
+}
+
+// newFromConfig 根据配置创建扫描器，配置了多个账号时返回账号池
+func newFromConfig(cfg *config.Config) cse.Scanner {
+	opts := cfg.HTTPOptions("censys")
+	accounts := cfg.CensysKeys()
+	switch len(accounts) {
+	case 0:
+		return NewScanner(cfg.CensysAPIID, cfg.CensysSecret, opts...)
+	case 1:
+		return NewScanner(accounts[0].ID, accounts[0].Secret, opts...)
+	}
+	var members []cse.PoolMember
+	for _, account := range accounts {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(account.ID), Scanner: NewScanner(account.ID, account.Secret, opts...)})
+	}
+	return cse.NewKeyPool(members...)
+}
@@ -0,0 +1,244 @@
+package censys
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"cscan/internal/common/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL Censys Search API 默认地址
+const DefaultBaseURL = "https://search.censys.io"
+
+// MaxPageSize hosts search 单页最多返回的结果数
+const MaxPageSize = 100
+
+type Scanner struct {
+	httpopt.Client
+	apiID  string
+	secret string
+}
+
+func NewScanner(apiID, secret string, opts ...httpopt.Option) *Scanner {
+	return &Scanner{
+		Client: httpopt.New(DefaultBaseURL, opts...),
+		apiID:  apiID,
+		secret: secret,
+	}
+}
+
+func (s *Scanner) Name() string {
+	return "Censys"
+}
+
+// Search 按页码搜索
+// Censys 只支持游标分页，这里从首页开始沿游标翻到第 page 页，每翻一页都会消耗一次请求；
+// SearchEngine 会优先使用 SearchCursor
+func (s *Scanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	cursor := ""
+	for i := 1; i < page; i++ {
+		_, next, err := s.SearchCursor(ctx, query, cursor, size)
+		if err != nil {
+			return nil, err
+		}
+		if next == "" {
+			return nil, nil
+		}
+		cursor = next
+	}
+
+	assets, _, err := s.SearchCursor(ctx, query, cursor, size)
+	return assets, err
+}
+
+// SearchCursor 调用 hosts search v2 接口，返回本页结果和下一页游标
+func (s *Scanner) SearchCursor(ctx context.Context, query, cursor string, size int) ([]model.Asset, string, error) {
+	body, err := s.Fetch(ctx, query, 0, cursor, size)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.Parse(body)
+}
+
+// Fetch 从 cursor 开始请求一页搜索结果并检查接口错误，返回原始响应体，Censys 按游标分页，忽略 page
+func (s *Scanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
+	if size <= 0 || size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("per_page", fmt.Sprintf("%d", size))
+	if cursor != "" {
+		params.Add("cursor", cursor)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/api/v2/hosts/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(s.apiID, s.secret)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var result struct {
+		Code   int    `json:"code"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	if result.Code != 200 || result.Error != "" {
+		message := result.Error
+		if message == "" {
+			message = result.Status
+		}
+		return nil, cerrors.FromAPI(s.Name(), resp, message)
+	}
+
+	return body, nil
+}
+
+// Parse 将 Fetch 返回的响应体解析为资产列表，并返回下一页游标
+func (s *Scanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var result struct {
+		Result struct {
+			Hits []struct {
+				IP       string `json:"ip"`
+				Name     string `json:"name"` // 虚拟主机的域名
+				Services []struct {
+					Port              int    `json:"port"`
+					ServiceName       string `json:"service_name"`
+					TransportProtocol string `json:"transport_protocol"`
+					HTTP              *struct {
+						Response struct {
+							HTMLTitle  string `json:"html_title"`
+							StatusCode int    `json:"status_code"`
+						} `json:"response"`
+					} `json:"http"`
+				} `json:"services"`
+				Location struct {
+					Country  string `json:"country"`
+					Province string `json:"province"`
+					City     string `json:"city"`
+				} `json:"location"`
+				DNS struct {
+					Names      []string `json:"names"`
+					ReverseDNS struct {
+						Names []string `json:"names"`
+					} `json:"reverse_dns"`
+				} `json:"dns"`
+			} `json:"hits"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	// 每个服务对应一条资产
+	var assets []model.Asset
+	for _, hit := range result.Result.Hits {
+		domain := hit.Name
+		if domain == "" && len(hit.DNS.Names) > 0 {
+			domain = hit.DNS.Names[0]
+		}
+		if domain == "" && len(hit.DNS.ReverseDNS.Names) > 0 {
+			domain = hit.DNS.ReverseDNS.Names[0]
+		}
+		location := model.JoinLocation(hit.Location.Country, hit.Location.Province, hit.Location.City)
+
+		for _, service := range hit.Services {
+			asset := model.Asset{
+				IP:       hit.IP,
+				Domain:   domain,
+				Port:     fmt.Sprintf("%d", service.Port),
+				Service:  strings.ToLower(service.ServiceName),
+				Location: location,
+				Source:   s.Name(),
+			}
+			if service.HTTP != nil {
+				asset.Title = service.HTTP.Response.HTMLTitle
+				if service.HTTP.Response.StatusCode > 0 {
+					asset.StatusCode = fmt.Sprintf("%d", service.HTTP.Response.StatusCode)
+				}
+			}
+			assets = append(assets, asset)
+		}
+	}
+
+	return assets, result.Result.Links.Next, nil
+}
+
+// Quota 查询账户本月剩余的查询次数
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/api/v1/account", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(s.apiID, s.secret)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var result struct {
+		Error string `json:"error"`
+		Quota struct {
+			Used      int `json:"used"`
+			Allowance int `json:"allowance"`
+		} `json:"quota"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	if result.Error != "" {
+		return nil, cerrors.FromAPI(s.Name(), resp, result.Error)
+	}
+	if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+		return nil, se
+	}
+
+	return &model.Quota{
+		Remaining: result.Quota.Allowance - result.Quota.Used,
+		Unit:      "次",
+		Detail:    fmt.Sprintf("本月已用 %d/%d 次", result.Quota.Used, result.Quota.Allowance),
+	}, nil
+}
@@ -0,0 +1,139 @@
+package censys
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器
+func newTestScanner(t *testing.T, handler http.HandlerFunc) *Scanner {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewScanner("id", "secret", httpopt.WithBaseURL(server.URL))
+}
+
+// page 生成包含一个主机的搜索响应
+func page(ip, next string) string {
+	return fmt.Sprintf(`{
+		"code": 200,
+		"status": "OK",
+		"result": {
+			"total": 2,
+			"hits": [{
+				"ip": %q,
+				"services": [
+					{"port": 80, "service_name": "HTTP", "transport_protocol": "TCP",
+					 "http": {"response": {"html_title": "Welcome", "status_code": 200}}},
+					{"port": 22, "service_name": "SSH", "transport_protocol": "TCP"}
+				],
+				"location": {"country": "China", "province": "Beijing", "city": "Beijing"},
+				"dns": {"names": ["a.example.com"]}
+			}],
+			"links": {"next": %q}
+		}
+	}`, ip, next)
+}
+
+func TestSearchCursor(t *testing.T) {
+	s := newTestScanner(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "id" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v2/hosts/search" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			w.Write([]byte(page("1.1.1.1", "cursor-2")))
+		} else {
+			w.Write([]byte(page("2.2.2.2", "")))
+		}
+	})
+
+	assets, next, err := s.SearchCursor(context.Background(), "ip: 1.1.1.0/24", "", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "cursor-2" {
+		t.Errorf("next = %q, want cursor-2", next)
+	}
+	// 每个服务对应一条资产
+	if len(assets) != 2 {
+		t.Fatalf("len(assets) = %d, want 2", len(assets))
+	}
+	a := assets[0]
+	checks := []struct{ name, got, want string }{
+		{"IP", a.IP, "1.1.1.1"},
+		{"Port", a.Port, "80"},
+		{"Service", a.Service, "http"},
+		{"Title", a.Title, "Welcome"},
+		{"StatusCode", a.StatusCode, "200"},
+		{"Domain", a.Domain, "a.example.com"},
+		{"Location", a.Location, "China Beijing Beijing"},
+		{"Source", a.Source, "Censys"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	if assets[1].Port != "22" || assets[1].Title != "" {
+		t.Errorf("assets[1] = %+v", assets[1])
+	}
+
+	// 按页码搜索时沿游标翻页
+	assets, err = s.Search(context.Background(), "ip: 1.1.1.0/24", 2, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 2 || assets[0].IP != "2.2.2.2" {
+		t.Errorf("Search(page 2) = %+v", assets)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   cerrors.Kind
+	}{
+		{"鉴权失败", http.StatusUnauthorized, `{"code": 401, "status": "Unauthorized", "error": "Unauthorized"}`, cerrors.KindAuthFailed},
+		{"速率限制", http.StatusTooManyRequests, `{"code": 429, "status": "Too Many Requests", "error": "rate limit exceeded"}`, cerrors.KindRateLimited},
+		{"查询错误", http.StatusBadRequest, `{"code": 400, "status": "Bad Request", "error": "Invalid query"}`, cerrors.KindBadQuery},
+		{"服务端错误", http.StatusInternalServerError, `<html>error</html>`, cerrors.KindNetwork},
+		{"无法解析的响应", http.StatusOK, `{"code": 200, "result": {"hits": "oops"}}`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s := newTestScanner(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		})
+		_, _, err := s.SearchCursor(context.Background(), "services.port: 22", "", 50)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s := newTestScanner(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/account" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"email": "a@example.com", "quota": {"used": 30, "allowance": 250}}`))
+	})
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Remaining != 220 {
+		t.Errorf("Remaining = %d, want 220", quota.Remaining)
+	}
+}
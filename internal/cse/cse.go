@@ -1,7 +1,11 @@
 package cse
 
 import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/logger"
 	"cscan/internal/common/model"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -10,8 +14,9 @@ import (
 )
 
 type Target struct {
-	Value string
-	Type  string // "ip" 或 "domain"
+	Value  string
+	Type   string // "ip"、"ipv6"、"cidr"、"range"（IP 范围）、"asn"、"domain"（可注册域名及其子域名）、"host"（完整主机名）或 "query"（原生查询语句）
+	Engine string // 原生查询指定的引擎（子模块名），为空时发送给所有引擎
 }
 
 // Scanner 定义了网络空间搜索引擎的通用接口
@@ -20,7 +25,76 @@ type Scanner interface {
 	Name() string
 
 	// Search 执行搜索并返回资产列表
-	Search(query string, page, size int) ([]model.Asset, error)
+	Search(ctx context.Context, query string, page, size int) ([]model.Asset, error)
+}
+
+// QuotaScanner 支持查询账户剩余额度的扫描器
+type QuotaScanner interface {
+	Scanner
+
+	// Quota 返回账户当前的剩余额度
+	Quota(ctx context.Context) (*model.Quota, error)
+}
+
+// CursorScanner 使用游标而不是页码分页的扫描器
+type CursorScanner interface {
+	Scanner
+
+	// SearchCursor 从 cursor 开始获取一页结果（首页 cursor 为空），
+	// 返回下一页的游标，空字符串表示没有更多结果
+	SearchCursor(ctx context.Context, query, cursor string, size int) ([]model.Asset, string, error)
+}
+
+// RawScanner 可以分别请求和解析一页结果的扫描器
+// 缓存保存 Fetch 返回的原始响应体，命中时重新调用 Parse，解析逻辑的修复对已缓存的数据同样生效
+type RawScanner interface {
+	Scanner
+
+	// Fetch 请求一页结果并检查接口错误，返回原始响应体
+	// 按页码分页的引擎使用 page，游标分页的引擎使用 cursor（首页为空）
+	Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error)
+
+	// Parse 将 Fetch 返回的响应体解析为资产列表，游标分页的引擎同时返回下一页的游标
+	Parse(body []byte) ([]model.Asset, string, error)
+}
+
+// usesCursor 判断扫描器底层的引擎是否使用游标分页
+// 缓存、账号池等包装器总是实现 SearchCursor 并转发给底层扫描器，因此需要检查最内层的扫描器
+func usesCursor(scanner Scanner) bool {
+	for {
+		w, ok := scanner.(interface{ Unwrap() Scanner })
+		if !ok {
+			break
+		}
+		inner := w.Unwrap()
+		if inner == nil {
+			break
+		}
+		scanner = inner
+	}
+	_, ok := scanner.(CursorScanner)
+	return ok
+}
+
+// AsQuotaScanner 返回扫描器（或其包装的扫描器）的额度查询能力
+func AsQuotaScanner(scanner Scanner) (QuotaScanner, bool) {
+	return unwrapAs[QuotaScanner](scanner)
+}
+
+// unwrapAs 沿 Unwrap 链查找实现了指定类型的扫描器
+func unwrapAs[T any](scanner Scanner) (T, bool) {
+	for scanner != nil {
+		if v, ok := scanner.(T); ok {
+			return v, true
+		}
+		w, ok := scanner.(interface{ Unwrap() Scanner })
+		if !ok {
+			break
+		}
+		scanner = w.Unwrap()
+	}
+	var zero T
+	return zero, false
 }
 
 // 定义各平台的 API 调用间隔
@@ -30,79 +104,100 @@ const (
 	MaxRetryWait    = 60 * time.Second // 最大重试等待时间
 )
 
+// DefaultConcurrency 每个引擎默认的 worker 数量
+// 请求间隔仍由 APIRateLimit 保证，多个 worker 只是让等待与网络请求重叠
+const DefaultConcurrency = 3
+
 // APIRateLimit API 速率限制管理
 type APIRateLimit struct {
-	interval    time.Duration
-	lastRequest time.Time
-	retryCount  int
-	mu          sync.Mutex
+	baseInterval time.Duration
+	interval     time.Duration
+	concurrency  int
+	lastRequest  time.Time
+	retryCount   int
+	mu           sync.Mutex
 }
 
 // 创建速率限制管理器
-func newAPIRateLimit(interval time.Duration) *APIRateLimit {
+func newAPIRateLimit(interval time.Duration, concurrency int) *APIRateLimit {
+	if concurrency < 1 {
+		concurrency = 1
+	}
 	return &APIRateLimit{
-		interval:    interval,
-		lastRequest: time.Now(),
+		baseInterval: interval,
+		interval:     interval,
+		concurrency:  concurrency,
+		lastRequest:  time.Now(),
 	}
 }
 
-// 等待并自适应调整间隔
-func (r *APIRateLimit) wait() {
+// 等待并自适应调整间隔，上下文取消时立即返回
+func (r *APIRateLimit) wait(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	// 计算需要等待的时间
 	elapsed := time.Since(r.lastRequest)
 	if elapsed < r.interval {
-		time.Sleep(r.interval - elapsed)
+		if err := sleepContext(ctx, r.interval-elapsed); err != nil {
+			return err
+		}
 	}
 	r.lastRequest = time.Now()
+	return nil
 }
 
-// 处理错误并调整间隔
-func (r *APIRateLimit) handleError(err error) {
+// 处理错误并调整间隔，返回调整后的间隔
+// 速率限制类错误会让该引擎后续所有请求的间隔指数增长，直到下一次请求成功
+func (r *APIRateLimit) handleError(err error) time.Duration {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// 检查错误是否与速率限制或API错误相关
-	if isRateLimitError(err) || isAPIError(err) {
-		r.retryCount++
-		// 指数退避：每次错误将间隔时间翻倍，并增加随机抖动
-		backoff := time.Duration(1<<uint(r.retryCount)) * time.Second
-		jitter := time.Duration(rand.Int63n(1000)) * time.Millisecond
-		r.interval = backoff + jitter
+	// 额度耗尽、鉴权失败和查询错误放慢请求也无济于事
+	var se *cerrors.ScanError
+	if !errors.As(err, &se) || se.Kind != cerrors.KindRateLimited {
+		return r.interval
+	}
 
-		if r.interval > MaxRetryWait {
-			r.interval = MaxRetryWait
-		}
+	r.retryCount++
+	// 指数退避：每次错误将间隔时间翻倍，并增加随机抖动
+	backoff := r.baseInterval << uint(r.retryCount)
+	if backoff <= 0 || backoff > MaxRetryWait {
+		backoff = MaxRetryWait
+	}
+	jitter := time.Duration(rand.Int63n(1000)) * time.Millisecond
+	r.interval = backoff + jitter
 
-		// 立即等待一段时间
-		time.Sleep(r.interval)
+	// 服务端指定了等待时间时以其为准
+	if se.RetryAfter > r.interval {
+		r.interval = se.RetryAfter
+	}
+	if r.interval > MaxRetryWait {
+		r.interval = MaxRetryWait
 	}
+	return r.interval
 }
 
-// 检查是否为速率限制错误或API错误
-func isRateLimitError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "rate limit") ||
-		strings.Contains(errStr, "too many requests") ||
-		strings.Contains(errStr, "请求太多") ||
-		strings.Contains(errStr, "请求过于频繁") ||
-		strings.Contains(errStr, "429")
+// 请求成功后恢复初始间隔
+func (r *APIRateLimit) handleSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.retryCount = 0
+	r.interval = r.baseInterval
 }
 
-// 检查是否为API错误
-func isAPIError(err error) bool {
-	if err == nil {
-		return false
+// sleepContext 等待指定时间，上下文取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "API错误") ||
-		strings.Contains(errStr, "cannot unmarshal") ||
-		strings.Contains(errStr, "稍后再试")
 }
 
 // SearchEngine 网络空间搜索引擎管理器
@@ -110,30 +205,137 @@ type SearchEngine struct {
 	scanners    []Scanner
 	rateLimits  map[string]*APIRateLimit
 	rateLimitMu sync.RWMutex
+	budgets     map[string]*budget
+	retries     map[string]RetryPolicy
+	journal     *Journal
+	sink        Sink
+}
+
+// Sink 接收搜索过程中获取的结果，每获取一页调用一次，可能被多个 worker 同时调用
+type Sink interface {
+	Write(assets []model.Asset) error
 }
 
 // NewSearchEngine 创建新的搜索引擎管理器
 func NewSearchEngine(scanners ...Scanner) *SearchEngine {
 	rateLimits := make(map[string]*APIRateLimit)
+	budgets := make(map[string]*budget)
 	for _, scanner := range scanners {
-		interval := DefaultInterval
-		if scanner.Name() == "Zone" {
-			interval = ZoneInterval
+		// 同一引擎的多个扫描器（如 ZoomEye 主机搜索和 Web 搜索）使用同一账号，共享速率限制和额度
+		key := engineKey(scanner.Name())
+		if rateLimits[key] == nil {
+			interval := DefaultInterval
+			if scanner.Name() == "Zone" {
+				interval = ZoneInterval
+			}
+			rateLimits[key] = newAPIRateLimit(interval, DefaultConcurrency)
+		}
+		if budgets[key] == nil {
+			budgets[key] = newBudget(scanner.Name())
 		}
-		rateLimits[scanner.Name()] = newAPIRateLimit(interval)
 	}
 
 	return &SearchEngine{
 		scanners:   scanners,
 		rateLimits: rateLimits,
+		budgets:    budgets,
+		retries:    make(map[string]RetryPolicy),
 	}
 }
 
+// SetRetryPolicy 设置指定引擎的重试策略，name 为配置中的引擎名，不区分大小写，未设置的字段使用默认值
+func (e *SearchEngine) SetRetryPolicy(name string, policy RetryPolicy) {
+	e.retries[strings.ToLower(name)] = policy.withDefaults()
+}
+
+// retryPolicy 获取指定扫描器所属引擎的重试策略
+func (e *SearchEngine) retryPolicy(scannerName string) RetryPolicy {
+	if policy, ok := e.retries[engineKey(scannerName)]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// SetBudget 设置指定引擎本次运行的额度上限，name 为配置中的引擎名，不区分大小写，0 表示不限制
+// 同一引擎的所有扫描器共享该额度
+func (e *SearchEngine) SetBudget(name string, maxRequests, maxResults int) {
+	if b, ok := e.budgets[strings.ToLower(name)]; ok {
+		b.mu.Lock()
+		b.usage.MaxRequests = maxRequests
+		b.usage.MaxResults = maxResults
+		b.mu.Unlock()
+	}
+}
+
+// budget 获取指定扫描器所属引擎的额度
+func (e *SearchEngine) budget(scannerName string) *budget {
+	return e.budgets[engineKey(scannerName)]
+}
+
+// engineKey 返回扫描器所属引擎的注册名，用于按引擎共享额度和重试策略；
+// 未注册的扫描器使用小写的扫描器名称
+func engineKey(scannerName string) string {
+	if engine := EngineOf(scannerName); engine != "" {
+		return engine
+	}
+	return strings.ToLower(scannerName)
+}
+
+// Usage 返回各引擎的额度使用情况，顺序与扫描器一致，共享额度的扫描器只返回一次
+func (e *SearchEngine) Usage() []Usage {
+	var usages []Usage
+	seen := make(map[string]bool)
+	for _, scanner := range e.scanners {
+		if scanner == nil {
+			continue
+		}
+		key := engineKey(scanner.Name())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		usages = append(usages, e.budgets[key].snapshot())
+	}
+	return usages
+}
+
+// SetConcurrency 设置指定引擎每个扫描器的 worker 数量，name 为配置中的引擎名，不区分大小写
+func (e *SearchEngine) SetConcurrency(name string, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	e.rateLimitMu.RLock()
+	rateLimit := e.rateLimits[strings.ToLower(name)]
+	e.rateLimitMu.RUnlock()
+	if rateLimit != nil {
+		rateLimit.mu.Lock()
+		rateLimit.concurrency = concurrency
+		rateLimit.mu.Unlock()
+	}
+}
+
+// SetJournal 设置断点日志，已完成的页将直接使用日志中的结果
+func (e *SearchEngine) SetJournal(journal *Journal) {
+	e.journal = journal
+}
+
+// SetSink 设置结果输出，设置后每页结果获取后立即写入 sink，SearchTargets 不再在内存中保留结果
+func (e *SearchEngine) SetSink(sink Sink) {
+	e.sink = sink
+}
+
+// rateLimit 获取指定扫描器所属引擎的速率限制器
+func (e *SearchEngine) rateLimit(scannerName string) *APIRateLimit {
+	e.rateLimitMu.RLock()
+	defer e.rateLimitMu.RUnlock()
+	return e.rateLimits[engineKey(scannerName)]
+}
+
 // Search 使用所有可用的扫描器执行搜索
-func (e *SearchEngine) Search(query string, page, size int) ([]model.Asset, error) {
+func (e *SearchEngine) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
 	var results []model.Asset
 	for _, scanner := range e.scanners {
-		assets, err := scanner.Search(query, page, size)
+		assets, err := scanner.Search(ctx, query, page, size)
 		if err != nil {
 			continue
 		}
@@ -143,7 +345,11 @@ func (e *SearchEngine) Search(query string, page, size int) ([]model.Asset, erro
 }
 
 // SearchTargets 批量搜索目标
-func (e *SearchEngine) SearchTargets(targets []Target, maxPage, pageSize int) ([]model.Asset, error) {
+// 每个引擎拥有独立的 worker 池并行执行，worker 数量由该引擎的 APIRateLimit 决定，
+// 总耗时取决于最慢的引擎而不是所有引擎之和。
+// 单个引擎出错不影响其他引擎，各引擎的错误汇总后与已获取的结果一起返回。
+// 上下文被取消时停止搜索，并返回已获取的结果及 ctx.Err()；设置了 Sink 时结果已写入 sink，返回的结果为空
+func (e *SearchEngine) SearchTargets(ctx context.Context, targets []Target, maxPage, pageSize int) ([]model.Asset, error) {
 	var (
 		results []model.Asset
 		mu      sync.Mutex
@@ -151,33 +357,56 @@ func (e *SearchEngine) SearchTargets(targets []Target, maxPage, pageSize int) ([
 		errs    []error
 	)
 
-	// 使用通道来控制并发数
-	semaphore := make(chan struct{}, 1) // 限制为1个并发，保证顺序执行
-
-	for _, target := range targets {
-		wg.Add(1)
-		go func(t Target) {
-			defer wg.Done()
-
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() {
-				<-semaphore // 释放信号量
-			}()
+	for _, scanner := range e.scanners {
+		if scanner == nil {
+			continue
+		}
 
-			assets, err := e.searchSingle(t, maxPage, pageSize)
-			mu.Lock()
-			if err != nil {
-				errs = append(errs, err)
-			} else {
-				results = append(results, assets...)
+		rateLimit := e.rateLimit(scanner.Name())
+		rateLimit.mu.Lock()
+		workers := rateLimit.concurrency
+		rateLimit.mu.Unlock()
+
+		// 每个引擎独立的任务队列
+		jobs := make(chan Target)
+		go func(s Scanner) {
+			defer close(jobs)
+			for _, target := range targets {
+				// 指定了引擎的原生查询只发送给该引擎
+				if target.Engine != "" && target.Engine != EngineOf(s.Name()) {
+					continue
+				}
+				select {
+				case jobs <- target:
+				case <-ctx.Done():
+					return
+				}
 			}
-			mu.Unlock()
-		}(target)
+		}(scanner)
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(s Scanner) {
+				defer wg.Done()
+				for t := range jobs {
+					assets, err := e.searchSingle(ctx, s, t, maxPage, pageSize)
+					mu.Lock()
+					// 即使出错也保留已获取的部分结果
+					results = append(results, assets...)
+					if err != nil && ctx.Err() == nil {
+						errs = append(errs, err)
+					}
+					mu.Unlock()
+				}
+			}(scanner)
+		}
 	}
 
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
 	if len(errs) > 0 {
 		return results, fmt.Errorf("部分搜索失败: %v", errs)
 	}
@@ -185,69 +414,199 @@ func (e *SearchEngine) SearchTargets(targets []Target, maxPage, pageSize int) ([
 	return results, nil
 }
 
-func buildQuery(scannerName string, target Target) string {
-	switch scannerName {
-	case "Hunter":
-		if target.Type == "ip" {
-			return fmt.Sprintf(`ip="%s"`, target.Value)
+// searchSingle 使用单个扫描器搜索单个目标
+func (e *SearchEngine) searchSingle(ctx context.Context, scanner Scanner, target Target, maxPage, pageSize int) ([]model.Asset, error) {
+	var results []model.Asset
+
+	query := buildQuery(scanner.Name(), target)
+	logger.Printf("[%s] 搜索: %s\n", scanner.Name(), query)
+
+	// 获取该扫描器的额度
+	b := e.budget(scanner.Name())
+
+	// 游标分页的引擎（如 Censys）按上一页返回的游标翻页
+	cursorScanner, _ := scanner.(CursorScanner)
+	useCursor := cursorScanner != nil && usesCursor(scanner)
+	cursor := ""
+
+	// 缓存已在 lookupCache 中查询过，未命中时直接请求，不再重复读取缓存文件
+	cachedScanner, _ := scanner.(*CachedScanner)
+
+	for page := 1; page <= maxPage; page++ {
+		var (
+			assets []model.Asset
+			next   string
+		)
+
+		// 断点续扫：跳过已完成的页
+		if entry, ok := e.lookupJournal(scanner, target, query, page); ok {
+			logger.Printf("[%s] %s 第 %d 页已完成，使用断点记录 (%d 条)\n", scanner.Name(), target.Value, page, len(entry.Assets))
+			b.addCacheHit()
+			assets, next = entry.Assets, entry.Next
+		} else if cached, hit := e.lookupCache(scanner, query, page, cursor, pageSize, useCursor); hit {
+			// 命中本地缓存时不消耗额度，也无需等待速率限制
+			logger.Printf("[%s] %s 第 %d 页命中缓存 (%d 条)\n", scanner.Name(), target.Value, page, len(cached.assets))
+			b.addCacheHit()
+			assets, next = cached.assets, cached.next
+			e.record(scanner, target, query, page, cursor, next, assets, nil)
+		} else {
+			logger.Printf("[%s] %s 搜索第 %d 页...\n", scanner.Name(), target.Value, page)
+
+			var err error
+			assets, err = e.fetchPage(ctx, scanner, target, page, func(ctx context.Context) ([]model.Asset, error) {
+				if useCursor {
+					var (
+						pageAssets []model.Asset
+						err        error
+					)
+					if cachedScanner != nil {
+						pageAssets, next, err = cachedScanner.fetchCursor(ctx, query, cursor, pageSize)
+					} else {
+						pageAssets, next, err = cursorScanner.SearchCursor(ctx, query, cursor, pageSize)
+					}
+					return pageAssets, err
+				}
+				if cachedScanner != nil {
+					return cachedScanner.fetch(ctx, query, page, pageSize)
+				}
+				return scanner.Search(ctx, query, page, pageSize)
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return results, ctx.Err()
+				}
+				// 额度用尽后停止该引擎的翻页
+				if errors.Is(err, errBudgetExhausted) {
+					logger.Printf("[%s] 已达到本次运行的额度上限，跳过 %s 第 %d 页及之后的查询\n", scanner.Name(), target.Value, page)
+					break
+				}
+				e.record(scanner, target, query, page, cursor, "", nil, err)
+				logger.Printf("[%s] %s 第 %d 页查询失败: %v，该目标第 %d 页之后的结果缺失 (可使用 -resume 重试)\n",
+					scanner.Name(), target.Value, page, err, page)
+				return results, fmt.Errorf("[%s] %s 第 %d 页: %w", scanner.Name(), target.Value, page, err)
+			}
+			e.record(scanner, target, query, page, cursor, next, assets, nil)
 		}
-		return fmt.Sprintf(`domain.suffix="%s"`, target.Value)
-	case "FOFA":
-		if target.Type == "ip" {
-			return fmt.Sprintf(`ip="%s"`, target.Value)
+
+		if len(assets) == 0 {
+			break
+		}
+		if e.sink != nil {
+			// 写入失败时停止该目标，避免继续消耗额度
+			if err := e.sink.Write(assets); err != nil {
+				return results, fmt.Errorf("[%s] 写入 %s 的结果失败: %v", scanner.Name(), target.Value, err)
+			}
+		} else {
+			results = append(results, assets...)
 		}
-		return fmt.Sprintf(`domain="%s"`, target.Value)
-	case "Quake":
-		if target.Type == "ip" {
-			return fmt.Sprintf(`ip:%s`, target.Value)
+
+		if useCursor {
+			if next == "" {
+				break
+			}
+			cursor = next
 		}
-		return fmt.Sprintf(`domain:%s`, target.Value)
-	default:
-		return ""
 	}
+
+	return results, nil
+}
+
+// cachedPage 缓存中的一页结果
+type cachedPage struct {
+	assets []model.Asset
+	next   string
+}
+
+// lookupJournal 查询断点日志中已完成的页
+func (e *SearchEngine) lookupJournal(scanner Scanner, target Target, query string, page int) (JournalEntry, bool) {
+	if e.journal == nil {
+		return JournalEntry{}, false
+	}
+	return e.journal.Lookup(scanner.Name(), target, query, page)
 }
 
-// searchSingle 搜索单个目标
-func (e *SearchEngine) searchSingle(target Target, maxPage, pageSize int) ([]model.Asset, error) {
-	var results []model.Asset
+// lookupCache 查询本地缓存中的一页结果
+func (e *SearchEngine) lookupCache(scanner Scanner, query string, page int, cursor string, pageSize int, useCursor bool) (cachedPage, bool) {
+	cached, ok := scanner.(*CachedScanner)
+	if !ok {
+		return cachedPage{}, false
+	}
+	if useCursor {
+		assets, next, hit := cached.lookupCursor(query, cursor, pageSize)
+		return cachedPage{assets: assets, next: next}, hit
+	}
+	assets, hit := cached.lookup(query, page, pageSize)
+	return cachedPage{assets: assets}, hit
+}
 
-	for _, scanner := range e.scanners {
-		if scanner == nil {
-			continue
-		}
+// errBudgetExhausted 引擎本次运行的额度已用尽
+var errBudgetExhausted = errors.New("已达到本次运行的额度上限")
 
-		query := buildQuery(scanner.Name(), target)
-		fmt.Printf("使用 %s 搜索: %s\n", scanner.Name(), query)
+// fetchPage 通过 fetch 请求单页数据，临时错误按该引擎的重试策略重试
+func (e *SearchEngine) fetchPage(ctx context.Context, scanner Scanner, target Target, page int, fetch func(context.Context) ([]model.Asset, error)) ([]model.Asset, error) {
+	rateLimit := e.rateLimit(scanner.Name())
+	b := e.budget(scanner.Name())
+	policy := e.retryPolicy(scanner.Name())
 
-		// 获取该扫描器的速率限制器
-		e.rateLimitMu.RLock()
-		rateLimit := e.rateLimits[scanner.Name()]
-		e.rateLimitMu.RUnlock()
+	for attempt := 1; ; attempt++ {
+		if !b.acquire() {
+			return nil, errBudgetExhausted
+		}
 
-		for page := 1; page <= maxPage; page++ {
-			fmt.Printf("搜索第 %d 页...\n", page)
+		// 等待适当的时间间隔
+		if err := rateLimit.wait(ctx); err != nil {
+			return nil, err
+		}
 
-			// 等待适当的时间间隔
-			rateLimit.wait()
+		// 账号池切换账号重试时，每个额外的请求同样计入额度
+		assets, err := fetch(withAcquire(ctx, b.acquire))
+		if err == nil {
+			rateLimit.handleSuccess()
+			b.addResults(len(assets))
+			return assets, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 
-			assets, err := scanner.Search(query, page, pageSize)
-			if err != nil {
-				// 处理错误并调整速率
-				rateLimit.handleError(err)
-				fmt.Printf("查询出错: %v，已增加延迟至 %v\n", err, rateLimit.interval)
-				if page == 1 {
-					// 如果是第一页就失败，尝试继续其他扫描器
-					break
-				}
-				// 如果不是第一页，认为已经获取了部分数据，结束当前扫描器的查询
-				break
-			}
-			if len(assets) == 0 {
-				break
+		// 调整后续请求的间隔，并按重试策略决定是否重试本页
+		interval := rateLimit.handleError(err)
+		delay, retry := policy.next(attempt, err)
+		if !retry {
+			if attempt > 1 {
+				err = fmt.Errorf("重试 %d 次后仍失败: %w", attempt-1, err)
 			}
-			results = append(results, assets...)
+			return nil, err
+		}
+		logger.Printf("[%s] %s 第 %d 页查询出错: %v，%v 后重试 (%d/%d，请求间隔 %v)\n",
+			scanner.Name(), target.Value, page, err, delay.Round(time.Millisecond), attempt, policy.MaxAttempts-1, interval)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
 		}
 	}
+}
 
-	return results, nil
+// record 将一页的搜索结果写入断点日志
+func (e *SearchEngine) record(scanner Scanner, target Target, query string, page int, cursor, next string, assets []model.Asset, err error) {
+	if e.journal == nil {
+		return
+	}
+
+	entry := JournalEntry{
+		Target:  target.Value,
+		Type:    target.Type,
+		Query:   query,
+		Scanner: scanner.Name(),
+		Page:    page,
+		Cursor:  cursor,
+		Next:    next,
+		Done:    err == nil,
+		Assets:  assets,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := e.journal.Record(entry); err != nil {
+		logger.Printf("[%s] 写入断点日志失败: %v\n", scanner.Name(), err)
+	}
 }
@@ -0,0 +1,213 @@
+package cse
+
+import (
+	"context"
+	"cscan/internal/common/config"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/model"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// namedScanner 只有名称的测试扫描器
+type namedScanner string
+
+func (s namedScanner) Name() string { return string(s) }
+
+func (s namedScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	return nil, nil
+}
+
+// fakeScanner 由测试函数决定每页结果的扫描器
+type fakeScanner struct {
+	name   string
+	search func(ctx context.Context, query string, page int) ([]model.Asset, error)
+}
+
+func (s fakeScanner) Name() string { return s.name }
+
+func (s fakeScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	return s.search(ctx, query, page)
+}
+
+// withoutDelay 取消搜索引擎的请求间隔，避免测试等待
+func withoutDelay(e *SearchEngine) *SearchEngine {
+	for _, rateLimit := range e.rateLimits {
+		rateLimit.baseInterval, rateLimit.interval = 0, 0
+	}
+	return e
+}
+
+func TestSearchTargetsEnginesIndependent(t *testing.T) {
+	release := make(chan struct{})
+	fastDone := make(chan string, 3)
+
+	// 每个目标只有一页结果
+	onePage := func(source string) func(ctx context.Context, query string, page int) ([]model.Asset, error) {
+		return func(ctx context.Context, query string, page int) ([]model.Asset, error) {
+			if page > 1 {
+				return nil, nil
+			}
+			return []model.Asset{{IP: query, Source: source}}, nil
+		}
+	}
+	fast := onePage("Fast")
+	slow := onePage("Slow")
+
+	e := withoutDelay(NewSearchEngine(
+		fakeScanner{"Slow", func(ctx context.Context, query string, page int) ([]model.Asset, error) {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return slow(ctx, query, page)
+		}},
+		fakeScanner{"Fail", func(ctx context.Context, query string, page int) ([]model.Asset, error) {
+			return nil, cerrors.Wrap(cerrors.KindAuthFailed, "Fail", errors.New("invalid api key"))
+		}},
+		fakeScanner{"Fast", func(ctx context.Context, query string, page int) ([]model.Asset, error) {
+			if page > 1 {
+				fastDone <- query
+			}
+			return fast(ctx, query, page)
+		}},
+	))
+
+	targets := []Target{
+		{Value: "1.1.1.1", Type: "query"},
+		{Value: "2.2.2.2", Type: "query"},
+		{Value: "3.3.3.3", Type: "query"},
+	}
+	var (
+		results []model.Asset
+		err     error
+		done    = make(chan struct{})
+	)
+	go func() {
+		results, err = e.SearchTargets(context.Background(), targets, 2, 10)
+		close(done)
+	}()
+
+	// Slow 阻塞期间 Fast 仍能完成所有目标
+	for range targets {
+		select {
+		case <-fastDone:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Fast 被阻塞的引擎拖慢")
+		}
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SearchTargets 没有返回")
+	}
+
+	counts := make(map[string]int)
+	for _, asset := range results {
+		counts[asset.Source]++
+	}
+	if counts["Fast"] != 3 || counts["Slow"] != 3 || len(results) != 6 {
+		t.Errorf("results by source = %v, want 3 from Fast and Slow", counts)
+	}
+	if err == nil {
+		t.Fatal("Fail 的错误没有返回")
+	}
+	if got := strings.Count(err.Error(), "invalid api key"); got != len(targets) {
+		t.Errorf("error = %v, want one failure per target", err)
+	}
+}
+
+func TestBudgetStopsPaging(t *testing.T) {
+	tests := []struct {
+		name                    string
+		maxRequests, maxResults int
+		wantRequests            int
+		wantResults             int
+	}{
+		{"请求数上限", 3, 0, 3, 30},
+		{"结果数上限", 0, 25, 3, 30},
+		{"不限制", 0, 0, 5, 50},
+	}
+	for _, tt := range tests {
+		requests := 0
+		e := withoutDelay(NewSearchEngine(fakeScanner{"Paged", func(ctx context.Context, query string, page int) ([]model.Asset, error) {
+			requests++
+			assets := make([]model.Asset, 10)
+			for i := range assets {
+				assets[i] = model.Asset{IP: query, Port: strconv.Itoa(page*100 + i)}
+			}
+			return assets, nil
+		}}))
+		e.SetBudget("paged", tt.maxRequests, tt.maxResults)
+
+		results, err := e.SearchTargets(context.Background(), []Target{{Value: "1.1.1.1", Type: "query"}}, 5, 10)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		usage := e.Usage()[0]
+		if requests != tt.wantRequests || len(results) != tt.wantResults {
+			t.Errorf("%s: requests = %d, results = %d, want %d, %d", tt.name, requests, len(results), tt.wantRequests, tt.wantResults)
+		}
+		if usage.Requests != tt.wantRequests || usage.Results != tt.wantResults {
+			t.Errorf("%s: usage = %+v", tt.name, usage)
+		}
+		if wantExhausted := tt.maxRequests > 0 || tt.maxResults > 0; usage.Exhausted != wantExhausted {
+			t.Errorf("%s: Exhausted = %v, want %v", tt.name, usage.Exhausted, wantExhausted)
+		}
+	}
+}
+
+// 注册只在测试中使用的引擎，注册表不允许重复注册，因此只在 init 中注册一次
+func init() {
+	Register(Engine{
+		Name: "sharedtest",
+		New:  func(cfg *config.Config) []Scanner { return nil },
+		Queries: map[string]QueryBuilder{
+			"Shared-Host": func(t Target) string { return t.Value },
+			"Shared-Web":  func(t Target) string { return t.Value },
+		},
+	})
+}
+
+func TestBudgetAndRetrySharedByEngine(t *testing.T) {
+	e := NewSearchEngine(namedScanner("Shared-Host"), namedScanner("Shared-Web"), namedScanner("Other"))
+	e.SetBudget("SharedTest", 3, 0)
+	e.SetRetryPolicy("sharedtest", RetryPolicy{MaxAttempts: 7})
+
+	host, web := e.budget("Shared-Host"), e.budget("Shared-Web")
+	if host != web {
+		t.Fatal("同一引擎的扫描器没有共享额度")
+	}
+	if u := host.snapshot(); u.MaxRequests != 3 {
+		t.Errorf("MaxRequests = %d, want 3", u.MaxRequests)
+	}
+	if u := e.budget("Other").snapshot(); u.MaxRequests != 0 {
+		t.Errorf("Other MaxRequests = %d, want 0", u.MaxRequests)
+	}
+	if e.rateLimit("Shared-Host") != e.rateLimit("Shared-Web") {
+		t.Error("同一引擎的扫描器没有共享速率限制")
+	}
+	if e.rateLimit("Shared-Host") == e.rateLimit("Other") {
+		t.Error("不同引擎共享了速率限制")
+	}
+	e.SetConcurrency("SharedTest", 5)
+	if c := e.rateLimit("Shared-Web").concurrency; c != 5 {
+		t.Errorf("Shared-Web concurrency = %d, want 5", c)
+	}
+	if p := e.retryPolicy("Shared-Web"); p.MaxAttempts != 7 {
+		t.Errorf("Shared-Web MaxAttempts = %d, want 7", p.MaxAttempts)
+	}
+	if p := e.retryPolicy("Other"); p.MaxAttempts != DefaultRetryPolicy.MaxAttempts {
+		t.Errorf("Other MaxAttempts = %d, want default", p.MaxAttempts)
+	}
+
+	usages := e.Usage()
+	if len(usages) != 2 || usages[0].Name != "Shared-Host" || usages[1].Name != "Other" {
+		t.Errorf("Usage() = %+v", usages)
+	}
+}
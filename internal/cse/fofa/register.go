@@ -0,0 +1,89 @@
+package fofa
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+)
+
+func init() {
+	cse.Register(cse.Engine{
+		Name:        "fofa",
+		Description: "FOFA 引擎",
+		ConfigKeys:  []string{"fofa_email", "fofa_api_key", "fofa_accounts"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.FofaKeys()) > 0
+		},
+		New: func(cfg *config.Config) []cse.Scanner {
+			return []cse.Scanner{newFromConfig(cfg)}
+		},
+		Queries: map[string]cse.QueryBuilder{
+			"FOFA": buildQuery,
+		},
+		Dialect: dialect,
+	})
+}
+
+// dialect FOFA 查询语法
+var dialect = &cse.Dialect{
+	Fields: map[string]string{
+		"ip":          "ip",
+		"port":        "port",
+		"domain":      "domain",
+		"host":        "host",
+		"title":       "title",
+		"body":        "body",
+		"header":      "header",
+		"server":      "server",
+		"status_code": "status_code",
+		"app":         "app",
+		"protocol":    "protocol",
+		"cert":        "cert",
+		"icp":         "icp",
+		"org":         "org",
+		"asn":         "asn",
+		"os":          "os",
+		"country":     "country",
+		"province":    "region",
+		"city":        "city",
+	},
+	Match:    "=",
+	NotMatch: "!=",
+	And:      " && ",
+	Or:       " || ",
+}
+
+// buildQuery 将目标转换为 FOFA 查询语句
+// FOFA 不支持 IP 范围，范围会被拆分为多个网段
+func buildQuery(target cse.Target) string {
+	switch target.Type {
+	case "ip", "ipv6", "cidr":
+		return fmt.Sprintf(`ip="%s"`, target.Value)
+	case "range":
+		return cse.ExpandRange(target.Value, func(cidr string) string {
+			return fmt.Sprintf(`ip="%s"`, cidr)
+		}, " || ")
+	case "asn":
+		return fmt.Sprintf(`asn="%s"`, target.Value)
+	case "host":
+		return fmt.Sprintf(`host="%s"`, target.Value)
+	}
+	return fmt.Sprintf(`domain="%s"`, target.Value)
+}
+
+// newFromConfig 根据配置创建扫描器，配置了多个账号时返回账号池
+func newFromConfig(cfg *config.Config) cse.Scanner {
+	opts := cfg.HTTPOptions("fofa")
+	accounts := cfg.FofaKeys()
+	switch len(accounts) {
+	case 0:
+		return NewScanner(cfg.FofaEmail, cfg.FofaAPIKey, opts...)
+	case 1:
+		return NewScanner(accounts[0].Email, accounts[0].Key, opts...)
+	}
+	var members []cse.PoolMember
+	for _, account := range accounts {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(account.Key), Scanner: NewScanner(account.Email, account.Key, opts...)})
+	}
+	return cse.NewKeyPool(members...)
+}
@@ -1,21 +1,30 @@
 package fofa
 
 import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
 	"cscan/internal/common/model"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 )
 
+// DefaultBaseURL FOFA API 默认地址
+const DefaultBaseURL = "https://fofa.info"
+
 type Scanner struct {
+	httpopt.Client
 	email  string
 	apiKey string
 }
 
-func NewScanner(email, apiKey string) *Scanner {
+func NewScanner(email, apiKey string, opts ...httpopt.Option) *Scanner {
 	return &Scanner{
+		Client: httpopt.New(DefaultBaseURL, opts...),
 		email:  email,
 		apiKey: apiKey,
 	}
@@ -25,37 +34,70 @@ func (s *Scanner) Name() string {
 	return "FOFA"
 }
 
-func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
-	baseURL := "https://fofa.info/api/v1/search/all"
+// Search 搜索资产
+func (s *Scanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	body, err := s.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := s.Parse(body)
+	return assets, err
+}
+
+// Fetch 请求一页搜索结果并检查接口错误，返回原始响应体，FOFA 按页码分页，忽略 cursor
+func (s *Scanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
 	queryBase64 := base64.StdEncoding.EncodeToString([]byte(query))
 
-	url := fmt.Sprintf("%s?email=%s&key=%s&qbase64=%s&page=%d&size=%d&fields=host,ip,port,protocol,title,icp,country,province,city",
-		baseURL, s.email, s.apiKey, queryBase64, page, size)
+	params := url.Values{}
+	params.Add("email", s.email)
+	params.Add("key", s.apiKey)
+	params.Add("qbase64", queryBase64)
+	params.Add("page", fmt.Sprintf("%d", page))
+	params.Add("size", fmt.Sprintf("%d", size))
+	params.Add("fields", "host,ip,port,protocol,title,icp,country,province,city")
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/api/v1/search/all?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
 	}
 
 	var result struct {
-		Error   bool       `json:"error"`
-		ErrMsg  string     `json:"errmsg"`
-		Results [][]string `json:"results"`
-		Fields  []string   `json:"fields"`
+		Error  bool   `json:"error"`
+		ErrMsg string `json:"errmsg"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
 
 	if result.Error {
-		return nil, fmt.Errorf("API错误: %v", result.ErrMsg)
+		return nil, cerrors.FromAPI(s.Name(), resp, result.ErrMsg)
+	}
+
+	return body, nil
+}
+
+// Parse 将 Fetch 返回的响应体解析为资产列表
+func (s *Scanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var result struct {
+		Results [][]string `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
 
 	var assets []model.Asset
@@ -71,24 +113,60 @@ func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
 			Service:  fields[3],
 			Title:    fields[4],
 			ICPOrg:   fields[5],
-			Location: joinNonEmpty([]string{fields[6], fields[7], fields[8]}),
+			Location: model.JoinLocation(fields[6], fields[7], fields[8]),
 			Source:   s.Name(),
 		}
 		assets = append(assets, asset)
 	}
 
-	return assets, nil
+	return assets, "", nil
 }
 
-func joinNonEmpty(parts []string) string {
-	var result string
-	for i, part := range parts {
-		if part != "" {
-			if i > 0 && result != "" {
-				result += " "
-			}
-			result += part
+// Quota 查询账户剩余的 API 查询次数
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	params := url.Values{}
+	params.Add("email", s.email)
+	params.Add("key", s.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/api/v1/info/my?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var result struct {
+		Error          bool   `json:"error"`
+		ErrMsg         string `json:"errmsg"`
+		FCoin          int    `json:"fcoin"`
+		IsVIP          bool   `json:"isvip"`
+		RemainAPIQuery int    `json:"remain_api_query"`
+		RemainAPIData  int    `json:"remain_api_data"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
 		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
-	return result
+
+	if result.Error {
+		return nil, cerrors.FromAPI(s.Name(), resp, result.ErrMsg)
+	}
+
+	return &model.Quota{
+		Remaining: result.RemainAPIQuery,
+		Unit:      "次",
+		Detail:    fmt.Sprintf("剩余数据 %d 条，F币 %d", result.RemainAPIData, result.FCoin),
+	}, nil
 }
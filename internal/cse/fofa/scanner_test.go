@@ -0,0 +1,109 @@
+package fofa
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器，handler 返回固定响应
+func newTestScanner(t *testing.T, status int, body string) (*Scanner, *http.Request) {
+	t.Helper()
+	var got http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewScanner("user@example.com", "secret", httpopt.WithBaseURL(server.URL)), &got
+}
+
+func TestSearch(t *testing.T) {
+	s, req := newTestScanner(t, http.StatusOK, `{
+		"error": false,
+		"size": 2,
+		"results": [
+			["www.example.com", "93.184.216.34", "443", "https", "Example Domain", "京ICP备00000000号", "中国", "北京", "北京市"],
+			["short", "1.1.1.1"]
+		]
+	}`)
+
+	assets, err := s.Search(context.Background(), `domain="example.com"`, 3, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.URL.Path; got != "/api/v1/search/all" {
+		t.Errorf("path = %s", got)
+	}
+	q := req.URL.Query()
+	if q.Get("email") != "user@example.com" || q.Get("key") != "secret" || q.Get("page") != "3" || q.Get("size") != "50" {
+		t.Errorf("query = %v", q)
+	}
+	if decoded, _ := base64.StdEncoding.DecodeString(q.Get("qbase64")); string(decoded) != `domain="example.com"` {
+		t.Errorf("qbase64 = %s", decoded)
+	}
+
+	// 字段数不足的结果被跳过
+	if len(assets) != 1 {
+		t.Fatalf("len(assets) = %d, want 1", len(assets))
+	}
+	a := assets[0]
+	checks := []struct{ name, got, want string }{
+		{"Domain", a.Domain, "www.example.com"},
+		{"IP", a.IP, "93.184.216.34"},
+		{"Port", a.Port, "443"},
+		{"Service", a.Service, "https"},
+		{"Title", a.Title, "Example Domain"},
+		{"ICPOrg", a.ICPOrg, "京ICP备00000000号"},
+		{"Location", a.Location, "中国 北京 北京市"},
+		{"Source", a.Source, "FOFA"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   cerrors.Kind
+	}{
+		{"无效的账号", http.StatusOK, `{"error": true, "errmsg": "[-700] Account Invalid"}`, cerrors.KindAuthFailed},
+		{"F点不足", http.StatusOK, `{"error": true, "errmsg": "[820031] F点余额不足"}`, cerrors.KindQuotaExhausted},
+		{"查询语法错误", http.StatusOK, `{"error": true, "errmsg": "[820000] 查询语法错误"}`, cerrors.KindBadQuery},
+		{"请求过于频繁", http.StatusOK, `{"error": true, "errmsg": "请求过于频繁"}`, cerrors.KindRateLimited},
+		{"状态码优先", http.StatusTooManyRequests, `{"error": true, "errmsg": "[-9] 未知错误"}`, cerrors.KindRateLimited},
+		{"服务端错误页面", http.StatusBadGateway, `<html>Bad Gateway</html>`, cerrors.KindNetwork},
+		{"无法解析的响应", http.StatusOK, `not json`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s, _ := newTestScanner(t, tt.status, tt.body)
+		_, err := s.Search(context.Background(), "port=22", 1, 100)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s, req := newTestScanner(t, http.StatusOK, `{"error": false, "fcoin": 5, "isvip": true, "remain_api_query": 9800, "remain_api_data": 490000}`)
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/api/v1/info/my" {
+		t.Errorf("path = %s", req.URL.Path)
+	}
+	if quota.Remaining != 9800 || quota.PerResult {
+		t.Errorf("quota = %+v, want 9800 次", quota)
+	}
+}
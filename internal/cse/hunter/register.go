@@ -0,0 +1,88 @@
+package hunter
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+)
+
+func init() {
+	cse.Register(cse.Engine{
+		Name:        "hunter",
+		Description: "Hunter 引擎",
+		ConfigKeys:  []string{"hunter_api_key", "hunter_api_keys"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.HunterKeys()) > 0
+		},
+		New: func(cfg *config.Config) []cse.Scanner {
+			return []cse.Scanner{newFromConfig(cfg)}
+		},
+		Queries: map[string]cse.QueryBuilder{
+			"Hunter": buildQuery,
+		},
+		Dialect: dialect,
+	})
+}
+
+// dialect Hunter 查询语法
+var dialect = &cse.Dialect{
+	Fields: map[string]string{
+		"ip":          "ip",
+		"port":        "ip.port",
+		"domain":      "domain.suffix",
+		"host":        "domain",
+		"title":       "web.title",
+		"body":        "web.body",
+		"header":      "header",
+		"server":      "header.server",
+		"status_code": "header.status_code",
+		"app":         "app.name",
+		"protocol":    "protocol",
+		"cert":        "cert",
+		"icp":         "icp.number",
+		"asn":         "as.number",
+		"os":          "ip.os",
+		"country":     "ip.country",
+		"province":    "ip.province",
+		"city":        "ip.city",
+	},
+	Match:    "=",
+	NotMatch: "!=",
+	And:      " && ",
+	Or:       " || ",
+}
+
+// buildQuery 将目标转换为 Hunter 查询语句
+// Hunter 不支持 IP 范围，范围会被拆分为多个网段
+func buildQuery(target cse.Target) string {
+	switch target.Type {
+	case "ip", "ipv6", "cidr":
+		return fmt.Sprintf(`ip="%s"`, target.Value)
+	case "range":
+		return cse.ExpandRange(target.Value, func(cidr string) string {
+			return fmt.Sprintf(`ip="%s"`, cidr)
+		}, " || ")
+	case "asn":
+		return fmt.Sprintf(`as.number="%s"`, target.Value)
+	case "host":
+		return fmt.Sprintf(`domain="%s"`, target.Value)
+	}
+	return fmt.Sprintf(`domain.suffix="%s"`, target.Value)
+}
+
+// newFromConfig 根据配置创建扫描器，配置了多个 Key 时返回账号池
+func newFromConfig(cfg *config.Config) cse.Scanner {
+	opts := cfg.HTTPOptions("hunter")
+	keys := cfg.HunterKeys()
+	switch len(keys) {
+	case 0:
+		return NewScanner(cfg.HunterAPIKey, opts...)
+	case 1:
+		return NewScanner(keys[0], opts...)
+	}
+	var members []cse.PoolMember
+	for _, key := range keys {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(key), Scanner: NewScanner(key, opts...)})
+	}
+	return cse.NewKeyPool(members...)
+}
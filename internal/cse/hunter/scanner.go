@@ -1,6 +1,9 @@
 package hunter
 
 import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
 	"cscan/internal/common/model"
 	"encoding/base64"
 	"encoding/json"
@@ -8,22 +11,47 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// DefaultBaseURL Hunter API 默认地址
+const DefaultBaseURL = "https://hunter.qianxin.com"
+
 type Scanner struct {
+	httpopt.Client
 	apiKey string
+
+	// 最近一次搜索响应中的剩余积分，-1 表示尚未获取
+	restQuota int
+	mu        sync.Mutex
 }
 
-func NewScanner(apiKey string) *Scanner {
-	return &Scanner{apiKey: apiKey}
+func NewScanner(apiKey string, opts ...httpopt.Option) *Scanner {
+	return &Scanner{
+		Client:    httpopt.New(DefaultBaseURL, opts...),
+		apiKey:    apiKey,
+		restQuota: -1,
+	}
 }
 
 func (s *Scanner) Name() string {
 	return "Hunter"
 }
 
-func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
-	baseURL := "https://hunter.qianxin.com/openApi/search"
+// Search 搜索资产
+func (s *Scanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	body, err := s.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := s.Parse(body)
+	return assets, err
+}
+
+// Fetch 请求一页搜索结果并检查接口错误，返回原始响应体，Hunter 按页码分页，忽略 cursor
+func (s *Scanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
 	searchBase64 := base64.StdEncoding.EncodeToString([]byte(query))
 
 	params := url.Values{}
@@ -32,37 +60,67 @@ func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
 	params.Add("page", fmt.Sprintf("%d", page))
 	params.Add("page_size", fmt.Sprintf("%d", size))
 
-	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/openApi/search?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
 	}
 
 	var result struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
 		Data    struct {
-			Arr []map[string]interface{} `json:"arr"`
+			RestQuota string `json:"rest_quota"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
 
 	if result.Code != 200 {
-		return nil, fmt.Errorf("API错误: %v", result.Message)
+		se := cerrors.FromAPI(s.Name(), resp, result.Message)
+		switch result.Code {
+		case 401:
+			se.Kind = cerrors.KindAuthFailed
+		case 429:
+			se.Kind = cerrors.KindRateLimited
+		}
+		return nil, se
+	}
+
+	// 记录响应中的剩余积分，如 "今日剩余积分：499"
+	if v, ok := parseQuota(result.Data.RestQuota); ok {
+		s.mu.Lock()
+		s.restQuota = v
+		s.mu.Unlock()
+	}
+
+	return body, nil
+}
+
+// Parse 将 Fetch 返回的响应体解析为资产列表
+func (s *Scanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var result struct {
+		Data struct {
+			Arr []map[string]interface{} `json:"arr"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
 
 	var assets []model.Asset
@@ -108,23 +166,47 @@ func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
 		if v, ok := item["city"].(string); ok && v != "" {
 			location = append(location, v)
 		}
-		asset.Location = joinNonEmpty(location)
+		asset.Location = model.JoinLocation(location...)
 
 		assets = append(assets, asset)
 	}
 
-	return assets, nil
+	return assets, "", nil
 }
 
-func joinNonEmpty(parts []string) string {
-	var result string
-	for i, part := range parts {
-		if part != "" {
-			if i > 0 && result != "" {
-				result += " "
-			}
-			result += part
+// Quota 返回剩余积分
+// Hunter 没有独立的额度接口，剩余积分来自搜索响应的 rest_quota 字段；
+// 尚未搜索过时返回 ErrQuotaUnknown，不发起额外的查询，避免为查询额度而消耗积分
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	s.mu.Lock()
+	remaining := s.restQuota
+	s.mu.Unlock()
+
+	if remaining < 0 {
+		return nil, fmt.Errorf("%w，Hunter 的剩余积分在首次搜索后才能获取", cerrors.ErrQuotaUnknown)
+	}
+
+	return &model.Quota{
+		Remaining: remaining,
+		Unit:      "积分",
+		PerResult: true,
+	}, nil
+}
+
+// parseQuota 从 "今日剩余积分：499" 这样的文本中提取数字
+func parseQuota(text string) (int, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
 		}
+		return -1
+	}, text)
+	if digits == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
 	}
-	return result
+	return v, true
 }
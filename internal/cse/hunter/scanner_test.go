@@ -0,0 +1,142 @@
+package hunter
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器，handler 返回固定响应
+func newTestScanner(t *testing.T, status int, body string) (*Scanner, *http.Request) {
+	t.Helper()
+	var got http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewScanner("secret", httpopt.WithBaseURL(server.URL)), &got
+}
+
+func TestSearch(t *testing.T) {
+	s, req := newTestScanner(t, http.StatusOK, `{
+		"code": 200,
+		"message": "success",
+		"data": {
+			"rest_quota": "今日剩余积分：499",
+			"arr": [{
+				"ip": "93.184.216.34",
+				"domain": "www.example.com",
+				"port": 443,
+				"protocol": "https",
+				"web_title": "Example Domain",
+				"status_code": 200,
+				"icp": {"name": "示例科技有限公司"},
+				"country": "中国",
+				"province": "北京",
+				"city": ""
+			}]
+		}
+	}`)
+
+	assets, err := s.Search(context.Background(), `domain.suffix="example.com"`, 2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/openApi/search" {
+		t.Errorf("path = %s", req.URL.Path)
+	}
+	q := req.URL.Query()
+	if q.Get("api-key") != "secret" || q.Get("page") != "2" || q.Get("page_size") != "100" {
+		t.Errorf("query = %v", q)
+	}
+	if decoded, _ := base64.StdEncoding.DecodeString(q.Get("search")); string(decoded) != `domain.suffix="example.com"` {
+		t.Errorf("search = %s", decoded)
+	}
+
+	if len(assets) != 1 {
+		t.Fatalf("len(assets) = %d, want 1", len(assets))
+	}
+	a := assets[0]
+	checks := []struct{ name, got, want string }{
+		{"IP", a.IP, "93.184.216.34"},
+		{"Domain", a.Domain, "www.example.com"},
+		{"Port", a.Port, "443"},
+		{"Service", a.Service, "https"},
+		{"Title", a.Title, "Example Domain"},
+		{"StatusCode", a.StatusCode, "200"},
+		{"ICPOrg", a.ICPOrg, "示例科技有限公司"},
+		{"Location", a.Location, "中国 北京"},
+		{"Source", a.Source, "Hunter"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   cerrors.Kind
+	}{
+		{"无效的 API Key", http.StatusOK, `{"code": 401, "message": "令牌过期"}`, cerrors.KindAuthFailed},
+		{"请求过于频繁", http.StatusOK, `{"code": 429, "message": "请求太多啦，稍后再试试"}`, cerrors.KindRateLimited},
+		{"积分不足", http.StatusOK, `{"code": 40205, "message": "今日免费积分不足"}`, cerrors.KindQuotaExhausted},
+		{"查询语法错误", http.StatusOK, `{"code": 400, "message": "语法错误"}`, cerrors.KindBadQuery},
+		{"服务端错误页面", http.StatusBadGateway, `<html>Bad Gateway</html>`, cerrors.KindNetwork},
+		{"无法解析的响应", http.StatusOK, `not json`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s, _ := newTestScanner(t, tt.status, tt.body)
+		_, err := s.Search(context.Background(), `ip="1.1.1.1"`, 1, 10)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuotaDoesNotProbe(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"code":200,"message":"success","data":{"arr":[{"ip":"1.1.1.1","port":443}],"rest_quota":"今日剩余积分：499"}}`))
+	}))
+	defer server.Close()
+
+	s := NewScanner("key", httpopt.WithBaseURL(server.URL))
+	if _, err := s.Quota(context.Background()); !errors.Is(err, cerrors.ErrQuotaUnknown) {
+		t.Fatalf("Quota() before search error = %v, want ErrQuotaUnknown", err)
+	}
+	if requests != 0 {
+		t.Fatalf("Quota() sent %d requests, want 0", requests)
+	}
+
+	// 搜索响应中的剩余积分之后可直接读取
+	assets, err := s.Search(context.Background(), `ip="1.1.1.1"`, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0].Port != "443" {
+		t.Errorf("Search() = %+v", assets)
+	}
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Remaining != 499 {
+		t.Errorf("Remaining = %d, want 499", quota.Remaining)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
@@ -0,0 +1,211 @@
+package cse
+
+import (
+	"bufio"
+	"cscan/internal/common/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry 断点日志中的一条记录，对应 目标 × 扫描器 × 页
+type JournalEntry struct {
+	Target  string        `json:"target"`
+	Type    string        `json:"type"`
+	Query   string        `json:"query"` // 发送给引擎的查询语句
+	Scanner string        `json:"scanner"`
+	Page    int           `json:"page"`
+	Cursor  string        `json:"cursor,omitempty"` // 游标分页时本页使用的游标
+	Next    string        `json:"next,omitempty"`   // 游标分页时下一页的游标
+	Done    bool          `json:"done"`
+	Error   string        `json:"error,omitempty"`
+	Assets  []model.Asset `json:"assets,omitempty"`
+	Time    time.Time     `json:"time"`
+}
+
+// Journal 断点续扫日志，以 JSON Lines 格式追加写入磁盘
+// 内存中只保存每条记录的索引，已完成页的资产在恢复时按偏移量从日志文件中读取
+type Journal struct {
+	path    string
+	file    *os.File // 追加写入记录
+	reader  *os.File // 按偏移量读取记录
+	size    int64    // 日志中完整记录的总长度，即下一条记录的偏移量
+	entries map[string]journalIndex
+	mu      sync.Mutex
+}
+
+// journalIndex 一条记录在内存中的索引，任务本身由 map 的键标识
+type journalIndex struct {
+	offset int64 // 记录在日志文件中的偏移量
+	length int   // 记录的长度，不含换行符
+	done   bool
+	count  int // 该页的资产数量
+}
+
+// OpenJournal 打开断点日志
+// resume 为 true 时加载已有记录并在其后追加，否则清空旧日志重新开始
+func OpenJournal(path string, resume bool) (*Journal, error) {
+	j := &Journal{
+		path:    path,
+		entries: make(map[string]journalIndex),
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := j.load(); err != nil {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开断点日志失败: %v", err)
+	}
+	// 去掉崩溃时写了一半的最后一行，避免新记录接在它后面
+	if resume {
+		if err := file.Truncate(j.size); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("打开断点日志失败: %v", err)
+		}
+	}
+	reader, err := os.Open(path)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("打开断点日志失败: %v", err)
+	}
+	j.file, j.reader = file, reader
+	return j, nil
+}
+
+// load 读取已有的断点日志并建立索引，忽略无法解析的行
+func (j *Journal) load() error {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取断点日志失败: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// 没有换行符的最后一行是崩溃时写了一半的记录
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取断点日志失败: %v", err)
+		}
+
+		offset := j.size
+		j.size += int64(len(line))
+
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		j.index(entry, offset, len(line)-1)
+	}
+	return nil
+}
+
+// index 更新记录的索引，已完成的记录不会被之后的失败记录覆盖
+func (j *Journal) index(entry JournalEntry, offset int64, length int) {
+	key := entry.key()
+	if old, ok := j.entries[key]; ok && old.done && !entry.Done {
+		return
+	}
+	j.entries[key] = journalIndex{
+		offset: offset,
+		length: length,
+		done:   entry.Done,
+		count:  len(entry.Assets),
+	}
+}
+
+// Lookup 查询某个目标在指定扫描器下以 query 查询的某一页是否已完成，已完成时从日志文件读取该页的记录
+// 目标类型或查询语句不同（如修改了查询模板）时视为不同的任务，不会复用旧记录
+func (j *Journal) Lookup(scanner string, target Target, query string, page int) (JournalEntry, bool) {
+	key := JournalEntry{Scanner: scanner, Target: target.Value, Type: target.Type, Query: query, Page: page}.key()
+
+	j.mu.Lock()
+	idx, ok := j.entries[key]
+	j.mu.Unlock()
+	if !ok || !idx.done {
+		return JournalEntry{}, false
+	}
+
+	// 读取失败时视为未完成，重新请求该页
+	data := make([]byte, idx.length)
+	if _, err := j.reader.ReadAt(data, idx.offset); err != nil {
+		return JournalEntry{}, false
+	}
+	var entry JournalEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.key() != key {
+		return JournalEntry{}, false
+	}
+	return entry, true
+}
+
+// Record 追加一条记录并立即同步到磁盘
+func (j *Journal) Record(entry JournalEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化断点记录失败: %v", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入断点日志失败: %v", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("同步断点日志失败: %v", err)
+	}
+
+	offset := j.size
+	j.size += int64(len(data)) + 1
+	j.index(entry, offset, len(data))
+	return nil
+}
+
+// Completed 返回已完成的页数及这些页的结果数
+func (j *Journal) Completed() (pages, assets int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, idx := range j.entries {
+		if idx.done {
+			pages++
+			assets += idx.count
+		}
+	}
+	return pages, assets
+}
+
+// Path 返回断点日志路径
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Close 关闭断点日志
+func (j *Journal) Close() error {
+	j.reader.Close()
+	return j.file.Close()
+}
+
+// key 返回记录对应任务的唯一标识：扫描器、目标类型、目标、查询语句和页码
+func (e JournalEntry) key() string {
+	return fmt.Sprintf("%s|%s|%s|%s|%d", e.Scanner, e.Type, e.Target, e.Query, e.Page)
+}
@@ -0,0 +1,109 @@
+package cse
+
+import (
+	"cscan/internal/common/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournalLookupMatchesTypeAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.journal")
+	j, err := OpenJournal(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := Target{Value: "example.com", Type: "domain"}
+	if err := j.Record(JournalEntry{Target: target.Value, Type: target.Type, Query: `domain="example.com"`, Scanner: "FOFA", Page: 1, Done: true}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	j, err = OpenJournal(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	tests := []struct {
+		name   string
+		target Target
+		query  string
+		want   bool
+	}{
+		{"相同任务", target, `domain="example.com"`, true},
+		{"不同类型", Target{Value: "example.com", Type: "host"}, `domain="example.com"`, false},
+		{"不同查询", target, `host="example.com"`, false},
+	}
+	for _, tt := range tests {
+		if _, ok := j.Lookup("FOFA", tt.target, tt.query, 1); ok != tt.want {
+			t.Errorf("%s: Lookup() = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestJournalResumeReadsAssetsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.journal")
+	j, err := OpenJournal(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := Target{Value: "1.1.1.1", Type: "ip"}
+	assets := []model.Asset{{IP: "1.1.1.1", Port: "80"}, {IP: "1.1.1.1", Port: "443"}}
+	entries := []JournalEntry{
+		{Target: target.Value, Type: target.Type, Query: "ip=1.1.1.1", Scanner: "FOFA", Page: 1, Done: true, Assets: assets},
+		{Target: target.Value, Type: target.Type, Query: "ip=1.1.1.1", Scanner: "FOFA", Page: 1, Error: "timeout"},
+		{Target: target.Value, Type: target.Type, Query: "ip=1.1.1.1", Scanner: "FOFA", Page: 2, Error: "timeout"},
+	}
+	for _, entry := range entries {
+		if err := j.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.Close()
+
+	// 模拟崩溃时写了一半的最后一行
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"target":"1.1.1.1","scanner":"FO`)
+	f.Close()
+
+	j, err = OpenJournal(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages, n := j.Completed(); pages != 1 || n != 2 {
+		t.Errorf("Completed() = %d, %d, want 1, 2", pages, n)
+	}
+
+	// 之后的失败记录不会覆盖已完成的页，资产从日志文件中读取
+	entry, ok := j.Lookup("FOFA", target, "ip=1.1.1.1", 1)
+	if !ok || !reflect.DeepEqual(entry.Assets, assets) {
+		t.Errorf("Lookup(page 1) = %v, %v, want %v", entry.Assets, ok, assets)
+	}
+	if _, ok := j.Lookup("FOFA", target, "ip=1.1.1.1", 2); ok {
+		t.Error("失败的页不应视为已完成")
+	}
+
+	// 恢复后追加的记录不会接在写了一半的行后面
+	page2 := []model.Asset{{IP: "1.1.1.1", Port: "8080"}}
+	if err := j.Record(JournalEntry{Target: target.Value, Type: target.Type, Query: "ip=1.1.1.1", Scanner: "FOFA", Page: 2, Done: true, Assets: page2}); err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := j.Lookup("FOFA", target, "ip=1.1.1.1", 2); !ok || !reflect.DeepEqual(entry.Assets, page2) {
+		t.Errorf("Lookup(page 2) = %v, %v, want %v", entry.Assets, ok, page2)
+	}
+	j.Close()
+
+	j, err = OpenJournal(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if pages, n := j.Completed(); pages != 2 || n != 3 {
+		t.Errorf("Completed() = %d, %d, want 2, 3", pages, n)
+	}
+}
@@ -0,0 +1,253 @@
+package cse
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/logger"
+	"cscan/internal/common/model"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PoolMember 账号池中的一个账号
+type PoolMember struct {
+	Label   string // 用于展示的账号标识，如脱敏后的 API Key
+	Scanner Scanner
+}
+
+// KeyUsage 单个账号本次运行的使用情况
+type KeyUsage struct {
+	Label     string
+	Requests  int
+	Results   int
+	Exhausted bool
+	LastError string
+}
+
+// KeyPool 同一引擎的多个账号，按顺序使用，当前账号不可用时自动切换
+type KeyPool struct {
+	members []PoolMember
+	usage   []KeyUsage
+	current int
+	mu      sync.Mutex
+}
+
+// NewKeyPool 创建账号池，所有成员必须属于同一引擎
+func NewKeyPool(members ...PoolMember) *KeyPool {
+	usage := make([]KeyUsage, len(members))
+	for i, m := range members {
+		usage[i].Label = m.Label
+	}
+	return &KeyPool{
+		members: members,
+		usage:   usage,
+	}
+}
+
+// Name 返回引擎名称
+func (p *KeyPool) Name() string {
+	if len(p.members) == 0 {
+		return ""
+	}
+	return p.members[0].Scanner.Name()
+}
+
+// Search 使用当前账号搜索，账号不可用时切换到下一个账号重试
+func (p *KeyPool) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	var assets []model.Asset
+	err := p.run(ctx, func(scanner Scanner) (int, error) {
+		var err error
+		assets, err = scanner.Search(ctx, query, page, size)
+		return len(assets), err
+	})
+	return assets, err
+}
+
+// SearchCursor 使用当前账号按游标搜索，账号不可用时切换到下一个账号重试
+func (p *KeyPool) SearchCursor(ctx context.Context, query, cursor string, size int) ([]model.Asset, string, error) {
+	var (
+		assets []model.Asset
+		next   string
+	)
+	err := p.run(ctx, func(scanner Scanner) (int, error) {
+		cs, ok := scanner.(CursorScanner)
+		if !ok {
+			return 0, fmt.Errorf("%s 不支持游标分页", scanner.Name())
+		}
+		var err error
+		assets, next, err = cs.SearchCursor(ctx, query, cursor, size)
+		return len(assets), err
+	})
+	return assets, next, err
+}
+
+// Fetch 使用当前账号请求原始响应，账号不可用时切换到下一个账号重试
+func (p *KeyPool) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
+	var body []byte
+	err := p.run(ctx, func(scanner Scanner) (int, error) {
+		raw, ok := scanner.(RawScanner)
+		if !ok {
+			return 0, fmt.Errorf("%s 不支持获取原始响应", scanner.Name())
+		}
+		var err error
+		body, err = raw.Fetch(ctx, query, page, cursor, size)
+		if err != nil {
+			return 0, err
+		}
+		// 解析结果只用于统计该账号返回的结果数
+		assets, _, _ := raw.Parse(body)
+		return len(assets), nil
+	})
+	return body, err
+}
+
+// Parse 使用第一个账号的扫描器解析响应，同一引擎各账号的响应格式相同
+func (p *KeyPool) Parse(body []byte) ([]model.Asset, string, error) {
+	raw, ok := p.Unwrap().(RawScanner)
+	if !ok {
+		return nil, "", fmt.Errorf("%s 不支持解析原始响应", p.Name())
+	}
+	return raw.Parse(body)
+}
+
+// Do 依次使用可用账号执行 call，call 返回本次请求获取的结果数
+// 供不实现 Scanner 搜索接口的引擎（如 0.zone 的公司搜索）复用账号切换逻辑
+func (p *KeyPool) Do(ctx context.Context, call func(Scanner) (int, error)) error {
+	return p.run(ctx, call)
+}
+
+// run 依次使用可用账号执行请求
+// 额度耗尽或鉴权失败的账号在本次运行中不再使用；触发速率限制的账号只在本次请求中跳过
+func (p *KeyPool) run(ctx context.Context, call func(Scanner) (int, error)) error {
+	tried := make(map[int]bool)
+	var lastErr error
+	for {
+		idx, ok := p.pick(tried)
+		if !ok {
+			if lastErr == nil {
+				lastErr = cerrors.New(cerrors.KindQuotaExhausted, p.Name(), "所有 API Key 的额度均已耗尽")
+			}
+			return lastErr
+		}
+		// 第一次请求的额度已由调用方预占，切换账号后的每次请求都要再预占额度
+		if len(tried) > 0 && !acquireRequest(ctx) {
+			return errBudgetExhausted
+		}
+		tried[idx] = true
+
+		n, err := call(p.members[idx].Scanner)
+
+		p.mu.Lock()
+		p.usage[idx].Requests++
+		if err == nil {
+			p.usage[idx].Results += n
+			p.mu.Unlock()
+			return nil
+		}
+		p.usage[idx].LastError = err.Error()
+		var se *cerrors.ScanError
+		exhausted := errors.As(err, &se) &&
+			(se.Kind == cerrors.KindQuotaExhausted || se.Kind == cerrors.KindAuthFailed)
+		rotate := ctx.Err() == nil && (exhausted || cerrors.KindOf(err) == cerrors.KindRateLimited)
+		if exhausted {
+			p.usage[idx].Exhausted = true
+		}
+		if rotate && p.current == idx {
+			p.current = (idx + 1) % len(p.members)
+		}
+		p.mu.Unlock()
+
+		if !rotate {
+			return err
+		}
+		lastErr = err
+		if len(tried) < len(p.members) {
+			logger.Printf("[%s] API Key %s 不可用 (%v)，切换到下一个账号\n", p.Name(), p.members[idx].Label, err)
+		}
+	}
+}
+
+// pick 返回当前可用且本次请求尚未尝试过的账号
+func (p *KeyPool) pick(tried map[int]bool) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := 0; i < len(p.members); i++ {
+		idx := (p.current + i) % len(p.members)
+		if !p.usage[idx].Exhausted && !tried[idx] {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// Quota 汇总所有账号的剩余额度，查询失败的账号不计入
+func (p *KeyPool) Quota(ctx context.Context) (*model.Quota, error) {
+	var (
+		total    *model.Quota
+		lastErr  error
+		answered int
+		failed   int
+	)
+	for _, m := range p.members {
+		q, ok := AsQuotaScanner(m.Scanner)
+		if !ok {
+			continue
+		}
+		quota, err := q.Quota(ctx)
+		if err != nil {
+			lastErr = err
+			failed++
+			continue
+		}
+		if total == nil {
+			total = &model.Quota{Unit: quota.Unit, PerResult: quota.PerResult}
+		}
+		total.Remaining += quota.Remaining
+		answered++
+	}
+	if total == nil {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("不支持查询额度")
+		}
+		return nil, lastErr
+	}
+	// 只统计成功返回额度的账号
+	total.Detail = fmt.Sprintf("%d 个账号合计", answered)
+	if failed > 0 {
+		total.Detail += fmt.Sprintf("，%d 个账号查询失败", failed)
+	}
+	return total, nil
+}
+
+// Unwrap 返回第一个账号的扫描器，用于判断引擎支持的能力
+func (p *KeyPool) Unwrap() Scanner {
+	if len(p.members) == 0 {
+		return nil
+	}
+	return p.members[0].Scanner
+}
+
+// KeyUsage 返回各账号的使用情况
+func (p *KeyPool) KeyUsage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	usage := make([]KeyUsage, len(p.usage))
+	copy(usage, p.usage)
+	return usage
+}
+
+// AsKeyPool 返回扫描器（或其包装的扫描器）对应的账号池
+func AsKeyPool(scanner Scanner) (*KeyPool, bool) {
+	return unwrapAs[*KeyPool](scanner)
+}
+
+// MaskKey 对 API Key 脱敏，仅保留首尾各 4 位
+func MaskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
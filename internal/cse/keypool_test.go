@@ -0,0 +1,98 @@
+package cse
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/model"
+	"testing"
+)
+
+// quotaScanner 返回固定额度或错误的测试扫描器
+type quotaScanner struct {
+	remaining int
+	err       error
+}
+
+func (s *quotaScanner) Name() string { return "Test" }
+
+func (s *quotaScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	return nil, nil
+}
+
+func (s *quotaScanner) Quota(ctx context.Context) (*model.Quota, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &model.Quota{Remaining: s.remaining, Unit: "次"}, nil
+}
+
+func TestKeyPoolQuotaCountsAnsweredMembers(t *testing.T) {
+	pool := NewKeyPool(
+		PoolMember{Label: "a", Scanner: &quotaScanner{remaining: 100}},
+		PoolMember{Label: "b", Scanner: &quotaScanner{err: cerrors.New(cerrors.KindAuthFailed, "Test", "invalid key")}},
+		PoolMember{Label: "c", Scanner: &quotaScanner{remaining: 50}},
+		// 不支持查询额度的账号不计入失败数
+		PoolMember{Label: "d", Scanner: namedScanner("Test")},
+	)
+	quota, err := pool.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Remaining != 150 {
+		t.Errorf("Remaining = %d, want 150", quota.Remaining)
+	}
+	if want := "2 个账号合计，1 个账号查询失败"; quota.Detail != want {
+		t.Errorf("Detail = %q, want %q", quota.Detail, want)
+	}
+}
+
+// errScanner 搜索时返回固定错误的测试扫描器
+type errScanner struct {
+	err   error
+	calls int
+}
+
+func (s *errScanner) Name() string { return "Test" }
+
+func (s *errScanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []model.Asset{{IP: "1.1.1.1"}}, nil
+}
+
+func TestKeyPoolRotationCountsAgainstBudget(t *testing.T) {
+	limited := cerrors.New(cerrors.KindRateLimited, "Test", "too many requests")
+
+	tests := []struct {
+		name        string
+		maxRequests int
+		wantErr     bool
+		wantCalls   int
+	}{
+		{"额度足够", 2, false, 2},
+		{"额度不足以切换账号", 1, true, 1},
+	}
+	for _, tt := range tests {
+		first := &errScanner{err: limited}
+		second := &errScanner{}
+		pool := NewKeyPool(PoolMember{Label: "a", Scanner: first}, PoolMember{Label: "b", Scanner: second})
+
+		b := newBudget("Test")
+		b.usage.MaxRequests = tt.maxRequests
+		if !b.acquire() {
+			t.Fatalf("%s: 首次请求无法预占额度", tt.name)
+		}
+		_, err := pool.Search(withAcquire(context.Background(), b.acquire), "q", 1, 10)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Search() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if calls := first.calls + second.calls; calls != tt.wantCalls {
+			t.Errorf("%s: upstream calls = %d, want %d", tt.name, calls, tt.wantCalls)
+		}
+		if u := b.snapshot(); u.Requests != tt.wantCalls {
+			t.Errorf("%s: budget Requests = %d, want %d", tt.name, u.Requests, tt.wantCalls)
+		}
+	}
+}
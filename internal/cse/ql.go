@@ -0,0 +1,444 @@
+package cse
+
+import (
+	cerrors "cscan/internal/common/errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// CScan 统一查询语言
+//
+//	expr   := or
+//	or     := and { "OR" and }
+//	and    := unary { ["AND"] unary }      相邻条件默认为 AND
+//	unary  := "NOT" unary | "(" expr ")" | field ":" value
+//	value  := "带引号的字符串" | 不含空白、括号和引号的单词
+//
+// 关键字不区分大小写，例如 `title:"login" AND port:8443 AND NOT country:CN`；
+// 不带引号的值可以包含冒号，如 `ip:2001:db8::1`，包含空格的值必须加引号，如 `title:"sign in"`
+
+// QueryFields 统一查询语言支持的字段及说明
+var QueryFields = map[string]string{
+	"ip":          "IP 地址或网段",
+	"port":        "端口",
+	"domain":      "根域名",
+	"host":        "主机名",
+	"title":       "网页标题",
+	"body":        "网页正文",
+	"header":      "HTTP 响应头",
+	"server":      "Server 响应头",
+	"status_code": "HTTP 状态码",
+	"app":         "应用/组件指纹",
+	"protocol":    "协议/服务",
+	"cert":        "证书内容",
+	"icp":         "ICP 备案号或备案主体",
+	"org":         "所属组织",
+	"asn":         "自治系统号",
+	"os":          "操作系统",
+	"country":     "国家",
+	"province":    "省份",
+	"city":        "城市",
+}
+
+// Node 查询语句的语法树节点
+type Node interface {
+	node()
+}
+
+// Term 字段匹配条件，Negated 为 true 表示不匹配
+type Term struct {
+	Field   string
+	Value   string
+	Negated bool
+}
+
+// Binary 逻辑与/或，Op 为 "AND" 或 "OR"
+type Binary struct {
+	Op          string
+	Left, Right Node
+}
+
+// Not 逻辑非
+type Not struct {
+	X Node
+}
+
+func (*Term) node()   {}
+func (*Binary) node() {}
+func (*Not) node()    {}
+
+// ParseQL 解析统一查询语言，返回语法树
+func ParseQL(input string) (Node, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("查询语句为空")
+	}
+
+	p := &qlParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("位置 %d 附近存在多余的内容: %s", p.peek().pos, p.peek().text)
+	}
+	return node, nil
+}
+
+// Dialect 引擎查询语法，用于将统一查询语言编译为引擎的原生查询
+type Dialect struct {
+	// Fields 统一字段名到引擎字段名的映射，未出现的字段视为不支持
+	Fields map[string]string
+
+	// Match 字段与值之间的匹配运算符，如 "=" 或 ":"
+	Match string
+
+	// NotMatch 不匹配运算符，如 "!="，为空时使用 Not 前缀
+	NotMatch string
+
+	// Not 取反前缀，如 "NOT "，与 NotMatch 都为空时不支持 NOT
+	Not string
+
+	// And、Or 逻辑运算符，如 " && " 或 " AND "
+	And, Or string
+
+	// BareNumbers 纯数字的值不加引号，如 Quake 的 port:8443
+	BareNumbers bool
+}
+
+// Compile 将语法树编译为该方言的查询语句
+// 先用德摩根定律将 NOT 下推到字段条件上，再逐个转换字段；
+// 存在不支持的字段时返回 KindBadQuery 错误并列出所有不支持的字段
+func (d *Dialect) Compile(node Node) (string, error) {
+	node = pushNot(node, false)
+
+	var unsupported []string
+	seen := make(map[string]bool)
+	walkTerms(node, func(t *Term) {
+		if _, ok := d.Fields[t.Field]; !ok && !seen[t.Field] {
+			seen[t.Field] = true
+			unsupported = append(unsupported, t.Field)
+		}
+		if t.Negated && d.NotMatch == "" && d.Not == "" && !seen["NOT"] {
+			seen["NOT"] = true
+			unsupported = append(unsupported, "NOT")
+		}
+	})
+	if len(unsupported) > 0 {
+		return "", fmt.Errorf("不支持: %s", strings.Join(unsupported, ", "))
+	}
+
+	return d.compile(node, ""), nil
+}
+
+func (d *Dialect) compile(node Node, parentOp string) string {
+	switch n := node.(type) {
+	case *Term:
+		value := n.Value
+		if !(d.BareNumbers && isNumber(value)) {
+			value = `"` + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), `"`, `\"`) + `"`
+		}
+		field := d.Fields[n.Field]
+		if !n.Negated {
+			return field + d.Match + value
+		}
+		if d.NotMatch != "" {
+			return field + d.NotMatch + value
+		}
+		return d.Not + field + d.Match + value
+	case *Binary:
+		sep := d.And
+		if n.Op == "OR" {
+			sep = d.Or
+		}
+		s := d.compile(n.Left, n.Op) + sep + d.compile(n.Right, n.Op)
+		// 与父节点运算符不同时加括号，避免依赖各引擎的运算符优先级
+		if parentOp != "" && parentOp != n.Op {
+			s = "(" + s + ")"
+		}
+		return s
+	}
+	return ""
+}
+
+// CompileQuery 将统一查询语言编译为每个引擎的原生查询
+// 返回的目标按引擎指定，可直接交给 SearchTargets；
+// 没有方言或包含不支持字段的引擎不会生成目标，原因按引擎名记录在 skipped 中
+func CompileQuery(input string, engines []Engine) (targets []Target, skipped map[string]error, err error) {
+	node, err := ParseQL(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	skipped = make(map[string]error)
+	for _, engine := range engines {
+		if engine.Dialect == nil {
+			skipped[engine.Name] = cerrors.New(cerrors.KindBadQuery, engine.Name, "不支持统一查询语言")
+			continue
+		}
+		query, err := engine.Dialect.Compile(node)
+		if err != nil {
+			skipped[engine.Name] = cerrors.New(cerrors.KindBadQuery, engine.Name, err.Error())
+			continue
+		}
+		targets = append(targets, Target{Value: query, Type: "query", Engine: engine.Name})
+	}
+	return targets, skipped, nil
+}
+
+// QueryFieldNames 返回统一查询语言支持的字段名，按字母排序
+func QueryFieldNames() []string {
+	var names []string
+	for name := range QueryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pushNot 使用德摩根定律将取反下推到字段条件
+func pushNot(node Node, negate bool) Node {
+	switch n := node.(type) {
+	case *Term:
+		return &Term{Field: n.Field, Value: n.Value, Negated: n.Negated != negate}
+	case *Not:
+		return pushNot(n.X, !negate)
+	case *Binary:
+		op := n.Op
+		if negate {
+			if op == "AND" {
+				op = "OR"
+			} else {
+				op = "AND"
+			}
+		}
+		return &Binary{Op: op, Left: pushNot(n.Left, negate), Right: pushNot(n.Right, negate)}
+	}
+	return node
+}
+
+func walkTerms(node Node, fn func(*Term)) {
+	switch n := node.(type) {
+	case *Term:
+		fn(n)
+	case *Not:
+		walkTerms(n.X, fn)
+	case *Binary:
+		walkTerms(n.Left, fn)
+		walkTerms(n.Right, fn)
+	}
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// 词法分析
+
+type qlToken struct {
+	kind string // "word"、"string"、"(", ")", ":"
+	text string
+	pos  int
+}
+
+// end 返回记号结束的位置，用于判断相邻记号之间是否有空白
+func (t qlToken) end() int {
+	return t.pos + len([]rune(t.text))
+}
+
+func tokenize(input string) ([]qlToken, error) {
+	var tokens []qlToken
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ':':
+			tokens = append(tokens, qlToken{kind: string(r), text: string(r), pos: i})
+			i++
+		case r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("位置 %d 的引号未闭合", start)
+			}
+			i++
+			tokens = append(tokens, qlToken{kind: "string", text: sb.String(), pos: start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`():"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, qlToken{kind: "word", text: string(runes[start:i]), pos: start})
+		}
+	}
+	return tokens, nil
+}
+
+// 语法分析
+
+type qlParser struct {
+	tokens []qlToken
+	pos    int
+	last   *Term // 上一个解析出的字段条件，用于生成错误提示
+}
+
+func (p *qlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *qlParser) peek() qlToken {
+	if p.done() {
+		return qlToken{kind: "eof", pos: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+// keyword 判断下一个记号是否为指定关键字
+func (p *qlParser) keyword(kw string) bool {
+	t := p.peek()
+	return t.kind == "word" && strings.EqualFold(t.text, kw)
+}
+
+func (p *qlParser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *qlParser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for !p.done() && p.peek().kind != ")" && !p.keyword("OR") {
+		if p.keyword("AND") {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *qlParser) parseUnary() (Node, error) {
+	t := p.peek()
+	switch {
+	case p.keyword("NOT"):
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case t.kind == "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != ")" {
+			return nil, fmt.Errorf("位置 %d 的括号未闭合", t.pos)
+		}
+		p.pos++
+		return node, nil
+	case t.kind == "word":
+		return p.parseTerm()
+	case t.kind == "eof":
+		return nil, fmt.Errorf("查询语句不完整")
+	default:
+		return nil, fmt.Errorf("位置 %d 附近存在无效的内容: %s", t.pos, t.text)
+	}
+}
+
+func (p *qlParser) parseTerm() (Node, error) {
+	field := p.peek()
+	p.pos++
+	name := strings.ToLower(field.text)
+	_, known := QueryFields[name]
+
+	if p.peek().kind != ":" {
+		// 未加引号的值中含有空格时，空格后的部分会被当作下一个条件
+		if !known && p.last != nil {
+			return nil, fmt.Errorf("位置 %d 附近的 %s 不是字段条件，包含空格的值需要加引号，如 %s:\"%s %s\"",
+				field.pos, field.text, p.last.Field, p.last.Value, field.text)
+		}
+		if !known {
+			return nil, fmt.Errorf("位置 %d 附近的 %s 不是字段条件，条件的格式为 字段:值", field.pos, field.text)
+		}
+		return nil, fmt.Errorf("位置 %d 的字段 %s 后缺少冒号", field.pos, field.text)
+	}
+	if !known {
+		return nil, fmt.Errorf("未知字段: %s (可用字段: %s)", field.text, strings.Join(QueryFieldNames(), ", "))
+	}
+	p.pos++
+
+	value, ok := p.parseValue()
+	if !ok {
+		return nil, fmt.Errorf("位置 %d 的字段 %s 缺少值", field.pos, field.text)
+	}
+
+	term := &Term{Field: name, Value: value}
+	p.last = term
+	return term, nil
+}
+
+// parseValue 解析字段的值
+// 不带引号的值由紧邻的单词和冒号拼接而成，如 IPv6 地址 2001:db8::1
+func (p *qlParser) parseValue() (string, bool) {
+	t := p.peek()
+	if t.kind == "string" {
+		p.pos++
+		return t.text, true
+	}
+	if t.kind != "word" && t.kind != ":" {
+		return "", false
+	}
+
+	var sb strings.Builder
+	end := t.pos
+	for !p.done() {
+		t = p.peek()
+		if (t.kind != "word" && t.kind != ":") || t.pos != end {
+			break
+		}
+		sb.WriteString(t.text)
+		end = t.end()
+		p.pos++
+	}
+	return sb.String(), true
+}
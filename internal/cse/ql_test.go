@@ -0,0 +1,177 @@
+package cse
+
+import (
+	cerrors "cscan/internal/common/errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Node
+	}{
+		{`port:443`, &Term{Field: "port", Value: "443"}},
+		{`TITLE:"sign in"`, &Term{Field: "title", Value: "sign in"}},
+		{`title:"a \"b\""`, &Term{Field: "title", Value: `a "b"`}},
+		{`ip:2001:db8::1`, &Term{Field: "ip", Value: "2001:db8::1"}},
+		{`ip:::1`, &Term{Field: "ip", Value: "::1"}},
+		{`ip:2001:db8::/32 port:80`, &Binary{Op: "AND",
+			Left:  &Term{Field: "ip", Value: "2001:db8::/32"},
+			Right: &Term{Field: "port", Value: "80"},
+		}},
+		{`port:80 or port:443 and app:nginx`, &Binary{Op: "OR",
+			Left: &Term{Field: "port", Value: "80"},
+			Right: &Binary{Op: "AND",
+				Left:  &Term{Field: "port", Value: "443"},
+				Right: &Term{Field: "app", Value: "nginx"},
+			},
+		}},
+		{`NOT (country:CN OR country:US)`, &Not{X: &Binary{Op: "OR",
+			Left:  &Term{Field: "country", Value: "CN"},
+			Right: &Term{Field: "country", Value: "US"},
+		}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQL(tt.input)
+		if err != nil {
+			t.Errorf("ParseQL(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQL(%q) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseQLErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // 错误信息应包含的内容
+	}{
+		{``, "查询语句为空"},
+		{`title:"login`, "引号未闭合"},
+		{`(port:80`, "括号未闭合"},
+		{`port:80)`, "多余的内容"},
+		{`port:`, "缺少值"},
+		{`port 80`, "缺少冒号"},
+		{`foo:bar`, "未知字段: foo"},
+		{`title:a b`, `title:"a b"`},
+		{`hello`, "不是字段条件"},
+		{`port:80 AND`, "不完整"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQL(tt.input)
+		if err == nil {
+			t.Errorf("ParseQL(%q) 应返回错误", tt.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseQL(%q) error = %q, want containing %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestCompileQuery(t *testing.T) {
+	fofa := Engine{Name: "fofa", Dialect: &Dialect{
+		Fields:   map[string]string{"ip": "ip", "port": "port", "title": "title", "country": "country"},
+		Match:    "=",
+		NotMatch: "!=",
+		And:      " && ",
+		Or:       " || ",
+	}}
+	quake := Engine{Name: "quake", Dialect: &Dialect{
+		Fields:      map[string]string{"ip": "ip", "port": "port", "title": "title", "country": "country"},
+		Match:       ":",
+		Not:         "NOT ",
+		And:         " AND ",
+		Or:          " OR ",
+		BareNumbers: true,
+	}}
+	noNot := Engine{Name: "nonot", Dialect: &Dialect{
+		Fields: map[string]string{"ip": "ip", "port": "port", "title": "title", "country": "country"},
+		Match:  ":",
+		And:    " ",
+		Or:     " | ",
+	}}
+	noTitle := Engine{Name: "notitle", Dialect: &Dialect{
+		Fields: map[string]string{"ip": "ip", "port": "port"},
+		Match:  "=",
+		And:    " && ",
+		Or:     " || ",
+	}}
+	plain := Engine{Name: "plain"}
+	engines := []Engine{fofa, quake, noNot, noTitle, plain}
+
+	tests := []struct {
+		input   string
+		want    map[string]string
+		skipped []string
+	}{
+		{
+			input:   `title:"sign in" port:443`,
+			want:    map[string]string{"fofa": `title="sign in" && port="443"`, "quake": `title:"sign in" AND port:443`, "nonot": `title:"sign in" port:"443"`},
+			skipped: []string{"notitle", "plain"},
+		},
+		{
+			input: `ip:2001:db8::1 OR port:80`,
+			want: map[string]string{
+				"fofa":    `ip="2001:db8::1" || port="80"`,
+				"quake":   `ip:"2001:db8::1" OR port:80`,
+				"nonot":   `ip:"2001:db8::1" | port:"80"`,
+				"notitle": `ip="2001:db8::1" || port="80"`,
+			},
+			skipped: []string{"plain"},
+		},
+		{
+			// NOT 按德摩根定律下推，不同运算符之间加括号
+			input: `port:80 NOT (country:CN OR country:US)`,
+			want: map[string]string{
+				"fofa":  `port="80" && country!="CN" && country!="US"`,
+				"quake": `port:80 AND NOT country:"CN" AND NOT country:"US"`,
+			},
+			skipped: []string{"nonot", "notitle", "plain"},
+		},
+		{
+			input: `(port:80 OR port:443) title:x`,
+			want: map[string]string{
+				"fofa":  `(port="80" || port="443") && title="x"`,
+				"quake": `(port:80 OR port:443) AND title:"x"`,
+				"nonot": `(port:"80" | port:"443") title:"x"`,
+			},
+			skipped: []string{"notitle", "plain"},
+		},
+	}
+	for _, tt := range tests {
+		targets, skipped, err := CompileQuery(tt.input, engines)
+		if err != nil {
+			t.Errorf("CompileQuery(%q) error: %v", tt.input, err)
+			continue
+		}
+		got := make(map[string]string)
+		for _, target := range targets {
+			if target.Type != "query" {
+				t.Errorf("CompileQuery(%q) target type = %q, want query", tt.input, target.Type)
+			}
+			got[target.Engine] = target.Value
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CompileQuery(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if len(skipped) != len(tt.skipped) {
+			t.Errorf("CompileQuery(%q) skipped = %v, want %v", tt.input, skipped, tt.skipped)
+		}
+		for _, name := range tt.skipped {
+			if err, ok := skipped[name]; !ok {
+				t.Errorf("CompileQuery(%q) 未跳过 %s", tt.input, name)
+			} else if cerrors.KindOf(err) != cerrors.KindBadQuery {
+				t.Errorf("CompileQuery(%q) skipped[%s] kind = %v, want KindBadQuery", tt.input, name, cerrors.KindOf(err))
+			}
+		}
+	}
+
+	if _, _, err := CompileQuery(`title:a b`, engines); err == nil {
+		t.Error("CompileQuery 应返回语法错误")
+	}
+}
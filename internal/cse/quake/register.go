@@ -0,0 +1,92 @@
+package quake
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+)
+
+func init() {
+	cse.Register(cse.Engine{
+		Name:        "quake",
+		Description: "Quake 引擎",
+		ConfigKeys:  []string{"quake_api_key", "quake_api_keys"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.QuakeKeys()) > 0
+		},
+		New: func(cfg *config.Config) []cse.Scanner {
+			return []cse.Scanner{newFromConfig(cfg)}
+		},
+		Queries: map[string]cse.QueryBuilder{
+			"Quake": buildQuery,
+		},
+		Dialect: dialect,
+	})
+}
+
+// dialect Quake 查询语法，取反使用 NOT 前缀，端口等数字不加引号
+var dialect = &cse.Dialect{
+	Fields: map[string]string{
+		"ip":          "ip",
+		"port":        "port",
+		"domain":      "domain",
+		"host":        "hostname",
+		"title":       "title",
+		"body":        "response",
+		"header":      "headers",
+		"server":      "server",
+		"status_code": "status_code",
+		"app":         "app",
+		"protocol":    "service",
+		"cert":        "cert",
+		"icp":         "icp",
+		"org":         "org",
+		"asn":         "asn",
+		"os":          "os",
+		"country":     "country",
+		"province":    "province",
+		"city":        "city",
+	},
+	Match:       ":",
+	Not:         "NOT ",
+	And:         " AND ",
+	Or:          " OR ",
+	BareNumbers: true,
+}
+
+// buildQuery 将目标转换为 Quake 查询语句
+// IPv6 地址和网段包含冒号或斜杠，需要加引号；IP 范围会被拆分为多个网段
+func buildQuery(target cse.Target) string {
+	switch target.Type {
+	case "ip":
+		return fmt.Sprintf(`ip:%s`, target.Value)
+	case "ipv6", "cidr":
+		return fmt.Sprintf(`ip:"%s"`, target.Value)
+	case "range":
+		return cse.ExpandRange(target.Value, func(cidr string) string {
+			return fmt.Sprintf(`ip:"%s"`, cidr)
+		}, " OR ")
+	case "asn":
+		return fmt.Sprintf(`asn:%s`, target.Value)
+	case "host":
+		return fmt.Sprintf(`hostname:"%s"`, target.Value)
+	}
+	return fmt.Sprintf(`domain:%s`, target.Value)
+}
+
+// newFromConfig 根据配置创建扫描器，配置了多个 Key 时返回账号池
+func newFromConfig(cfg *config.Config) cse.Scanner {
+	opts := cfg.HTTPOptions("quake")
+	keys := cfg.QuakeKeys()
+	switch len(keys) {
+	case 0:
+		return NewScanner(cfg.QuakeAPIKey, opts...)
+	case 1:
+		return NewScanner(keys[0], opts...)
+	}
+	var members []cse.PoolMember
+	for _, key := range keys {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(key), Scanner: NewScanner(key, opts...)})
+	}
+	return cse.NewKeyPool(members...)
+}
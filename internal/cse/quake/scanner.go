@@ -2,6 +2,9 @@ package quake
 
 import (
 	"bytes"
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
 	"cscan/internal/common/model"
 	"encoding/json"
 	"fmt"
@@ -9,21 +12,37 @@ import (
 	"net/http"
 )
 
+// DefaultBaseURL Quake API 默认地址
+const DefaultBaseURL = "https://quake.360.net"
+
 type Scanner struct {
+	httpopt.Client
 	apiKey string
 }
 
-func NewScanner(apiKey string) *Scanner {
-	return &Scanner{apiKey: apiKey}
+func NewScanner(apiKey string, opts ...httpopt.Option) *Scanner {
+	return &Scanner{
+		Client: httpopt.New(DefaultBaseURL, opts...),
+		apiKey: apiKey,
+	}
 }
 
 func (s *Scanner) Name() string {
 	return "Quake"
 }
 
-func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
-	baseURL := "https://quake.360.net/api/v3/search/quake_service"
+// Search 搜索资产
+func (s *Scanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	body, err := s.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := s.Parse(body)
+	return assets, err
+}
 
+// Fetch 请求一页搜索结果并检查接口错误，返回原始响应体，Quake 按页码分页，忽略 cursor
+func (s *Scanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
 	requestData := map[string]interface{}{
 		"query":  query,
 		"start":  (page - 1) * size,
@@ -36,7 +55,7 @@ func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", baseURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/api/v3/search/quake_service", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
@@ -44,30 +63,43 @@ func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
 	req.Header.Set("X-QuakeToken", s.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
 	}
 
 	var result struct {
-		Code    int           `json:"code"`
-		Message string        `json:"message"`
-		Data    []interface{} `json:"data"`
+		Code    interface{} `json:"code"` // 成功时为 0，失败时可能是 "q3005" 这样的字符串
+		Message string      `json:"message"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
 
-	if result.Code != 0 {
-		return nil, fmt.Errorf("API错误: %v", result.Message)
+	if fmt.Sprint(result.Code) != "0" {
+		return nil, cerrors.FromAPI(s.Name(), resp, result.Message)
+	}
+
+	return body, nil
+}
+
+// Parse 将 Fetch 返回的响应体解析为资产列表
+func (s *Scanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var result struct {
+		Data []interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
 	}
 
 	var assets []model.Asset
@@ -116,25 +148,59 @@ func (s *Scanner) Search(query string, page, size int) ([]model.Asset, error) {
 				if v, ok := location["city_cn"].(string); ok && v != "" {
 					parts = append(parts, v)
 				}
-				asset.Location = joinNonEmpty(parts)
+				asset.Location = model.JoinLocation(parts...)
 			}
 
 			assets = append(assets, asset)
 		}
 	}
 
-	return assets, nil
+	return assets, "", nil
 }
 
-func joinNonEmpty(parts []string) string {
-	var result string
-	for i, part := range parts {
-		if part != "" {
-			if i > 0 && result != "" {
-				result += " "
-			}
-			result += part
+// Quota 查询账户剩余积分
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/api/v3/user/info", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-QuakeToken", s.apiKey)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var result struct {
+		Code    interface{} `json:"code"`
+		Message string      `json:"message"`
+		Data    struct {
+			Credit           int `json:"credit"`
+			PersistentCredit int `json:"persistent_credit"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
 		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	if fmt.Sprint(result.Code) != "0" {
+		return nil, cerrors.FromAPI(s.Name(), resp, result.Message)
 	}
-	return result
+
+	return &model.Quota{
+		Remaining: result.Data.Credit + result.Data.PersistentCredit,
+		Unit:      "积分",
+		PerResult: true,
+		Detail:    fmt.Sprintf("月度积分 %d，长效积分 %d", result.Data.Credit, result.Data.PersistentCredit),
+	}, nil
 }
@@ -0,0 +1,122 @@
+package quake
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器，handler 返回固定响应，并记录请求头和请求体
+func newTestScanner(t *testing.T, status int, body string) (*Scanner, *http.Request, *[]byte) {
+	t.Helper()
+	var (
+		got     http.Request
+		reqBody []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		reqBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewScanner("secret", httpopt.WithBaseURL(server.URL)), &got, &reqBody
+}
+
+func TestSearch(t *testing.T) {
+	s, req, reqBody := newTestScanner(t, http.StatusOK, `{
+		"code": 0,
+		"message": "Successful.",
+		"data": [{
+			"ip": "93.184.216.34",
+			"port": 443,
+			"domain": "www.example.com",
+			"service": {"name": "http/ssl", "http": {"title": "Example Domain", "status_code": 200}},
+			"location": {"country_cn": "美国", "province_cn": "马萨诸塞州", "city_cn": ""}
+		}]
+	}`)
+
+	assets, err := s.Search(context.Background(), `domain:"example.com"`, 3, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost || req.URL.Path != "/api/v3/search/quake_service" {
+		t.Errorf("request = %s %s", req.Method, req.URL.Path)
+	}
+	if got := req.Header.Get("X-QuakeToken"); got != "secret" {
+		t.Errorf("X-QuakeToken = %q", got)
+	}
+	var sent struct {
+		Query string `json:"query"`
+		Start int    `json:"start"`
+		Size  int    `json:"size"`
+	}
+	if err := json.Unmarshal(*reqBody, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Query != `domain:"example.com"` || sent.Start != 40 || sent.Size != 20 {
+		t.Errorf("request body = %s", *reqBody)
+	}
+
+	if len(assets) != 1 {
+		t.Fatalf("len(assets) = %d, want 1", len(assets))
+	}
+	a := assets[0]
+	checks := []struct{ name, got, want string }{
+		{"IP", a.IP, "93.184.216.34"},
+		{"Port", a.Port, "443"},
+		{"Domain", a.Domain, "www.example.com"},
+		{"Service", a.Service, "http/ssl"},
+		{"Title", a.Title, "Example Domain"},
+		{"StatusCode", a.StatusCode, "200"},
+		{"Location", a.Location, "美国 马萨诸塞州"},
+		{"Source", a.Source, "Quake"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   cerrors.Kind
+	}{
+		{"无效的 Token", http.StatusUnauthorized, `{"code": "u3004", "message": "Token is invalid"}`, cerrors.KindAuthFailed},
+		{"积分不足", http.StatusOK, `{"code": "q3005", "message": "积分不足"}`, cerrors.KindQuotaExhausted},
+		{"查询语法错误", http.StatusOK, `{"code": "q2001", "message": "查询语句错误"}`, cerrors.KindBadQuery},
+		{"速率限制", http.StatusTooManyRequests, `{"code": "q3005", "message": "Too many requests"}`, cerrors.KindRateLimited},
+		{"服务端错误页面", http.StatusBadGateway, `<html>Bad Gateway</html>`, cerrors.KindNetwork},
+		{"无法解析的响应", http.StatusOK, `not json`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s, _, _ := newTestScanner(t, tt.status, tt.body)
+		_, err := s.Search(context.Background(), "port:22", 1, 10)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s, req, _ := newTestScanner(t, http.StatusOK, `{"code": 0, "data": {"credit": 3000, "persistent_credit": 200}}`)
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/api/v3/user/info" || req.Header.Get("X-QuakeToken") != "secret" {
+		t.Errorf("request = %s, token = %q", req.URL.Path, req.Header.Get("X-QuakeToken"))
+	}
+	if quota.Remaining != 3200 || !quota.PerResult {
+		t.Errorf("quota = %+v, want 3200 积分", quota)
+	}
+}
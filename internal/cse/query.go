@@ -0,0 +1,19 @@
+package cse
+
+import "strings"
+
+// ParseQuery 解析一条原生查询语句
+// 支持以 "引擎名:" 开头指定引擎，如 `fofa: title="xxx" && country="CN"`；
+// 冒号前不是已注册的引擎名时（如 Quake 的 `app:"nginx"`）整行作为查询语句发送给所有引擎
+func ParseQuery(line string) Target {
+	line = strings.TrimSpace(line)
+	target := Target{Value: line, Type: "query"}
+
+	if i := strings.Index(line, ":"); i > 0 {
+		if engine, ok := LookupEngine(line[:i]); ok {
+			target.Engine = engine.Name
+			target.Value = strings.TrimSpace(line[i+1:])
+		}
+	}
+	return target
+}
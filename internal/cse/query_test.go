@@ -0,0 +1,25 @@
+package cse
+
+import "testing"
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		line string
+		want Target
+	}{
+		{`alphatest: title="xxx" && country="CN"`, Target{Value: `title="xxx" && country="CN"`, Type: "query", Engine: "alphatest"}},
+		{`  BetaTest:app:"nginx"  `, Target{Value: `app:"nginx"`, Type: "query", Engine: "betatest"}},
+		// 查询语句本身包含冒号时只按第一个冒号拆分
+		{`betatest: title:"a:b" AND port:8443`, Target{Value: `title:"a:b" AND port:8443`, Type: "query", Engine: "betatest"}},
+		// 冒号前不是已注册的引擎名时整行作为查询语句
+		{`app:"nginx"`, Target{Value: `app:"nginx"`, Type: "query"}},
+		{`web.title="login" && ip="1.1.1.1"`, Target{Value: `web.title="login" && ip="1.1.1.1"`, Type: "query"}},
+		{`:alphatest`, Target{Value: `:alphatest`, Type: "query"}},
+		{`alphatest title="x"`, Target{Value: `alphatest title="x"`, Type: "query"}},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.line); got != tt.want {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
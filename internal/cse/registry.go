@@ -0,0 +1,184 @@
+package cse
+
+import (
+	"cscan/internal/common/config"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// QueryBuilder 将目标转换为引擎的查询语句
+type QueryBuilder func(target Target) string
+
+// Engine 可注册的网络空间搜索引擎
+type Engine struct {
+	// Name 子模块名称，如 "hunter"，用于 -m cse <name> 选择引擎
+	Name string
+
+	// Description 帮助信息中的说明
+	Description string
+
+	// ConfigKeys 引擎需要的配置项，配置缺失时用于提示
+	ConfigKeys []string
+
+	// Configured 判断配置中是否提供了引擎需要的 Key
+	Configured func(cfg *config.Config) bool
+
+	// New 根据配置创建扫描器，一个引擎可以提供多个扫描器（如 ZoomEye 的主机搜索和 Web 搜索）
+	New func(cfg *config.Config) []Scanner
+
+	// Queries 按扫描器名称（Scanner.Name()）注册的查询语句构造函数
+	Queries map[string]QueryBuilder
+
+	// Dialect 统一查询语言到引擎查询语法的转换规则，为空表示不支持统一查询语言
+	Dialect *Dialect
+}
+
+var (
+	registryMu     sync.RWMutex
+	engines        = make(map[string]Engine)
+	queryBuilders  = make(map[string]QueryBuilder)
+	scannerEngines = make(map[string]string) // 扫描器名称 -> 引擎名称
+)
+
+// Register 注册搜索引擎，通常在引擎包的 init 中调用
+// 名称重复或缺少构造函数时 panic
+func Register(engine Engine) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := strings.ToLower(engine.Name)
+	if name == "" || engine.New == nil {
+		panic("cse: Register 缺少引擎名称或构造函数")
+	}
+	if _, dup := engines[name]; dup {
+		panic("cse: 重复注册引擎 " + name)
+	}
+	engine.Name = name
+	engines[name] = engine
+
+	for scannerName, builder := range engine.Queries {
+		queryBuilders[scannerName] = builder
+		scannerEngines[scannerName] = name
+	}
+}
+
+// EngineOf 返回扫描器所属的引擎名称，未注册时返回空字符串
+func EngineOf(scannerName string) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return scannerEngines[scannerName]
+}
+
+// Engines 返回所有已注册的引擎，按名称排序
+func Engines() []Engine {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]Engine, 0, len(engines))
+	for _, engine := range engines {
+		list = append(list, engine)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// EngineNames 返回所有已注册引擎的名称
+func EngineNames() []string {
+	var names []string
+	for _, engine := range Engines() {
+		names = append(names, engine.Name)
+	}
+	return names
+}
+
+// LookupEngine 按名称查找引擎，不区分大小写
+func LookupEngine(name string) (Engine, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	engine, ok := engines[strings.ToLower(strings.TrimSpace(name))]
+	return engine, ok
+}
+
+// SelectEngines 解析逗号分隔的引擎列表，如 "hunter,fofa"
+// 列表为空时返回所有已配置 Key 的引擎，仍是默认占位值的引擎会被跳过
+func SelectEngines(list string, cfg *config.Config) ([]Engine, error) {
+	if strings.TrimSpace(list) == "" {
+		var selected []Engine
+		for _, engine := range Engines() {
+			if !engine.IsConfigured(cfg) {
+				continue
+			}
+			selected = append(selected, engine)
+		}
+		return selected, nil
+	}
+
+	var selected []Engine
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		engine, ok := LookupEngine(name)
+		if !ok {
+			return nil, fmt.Errorf("未知的子模块: %s (可用子模块: %s)", name, strings.Join(EngineNames(), ", "))
+		}
+		if seen[engine.Name] {
+			continue
+		}
+		seen[engine.Name] = true
+		selected = append(selected, engine)
+	}
+	return selected, nil
+}
+
+// ValidateEngines 检查所选引擎的配置
+// 显式选择的引擎必须已配置，使用默认引擎时至少需要一个引擎已配置
+func ValidateEngines(selected []Engine, cfg *config.Config, explicit bool) error {
+	configured := 0
+	for _, engine := range selected {
+		if engine.IsConfigured(cfg) {
+			configured++
+		} else if explicit {
+			return fmt.Errorf("引擎 %s 未配置，请在配置文件中设置 %s", engine.Name, strings.Join(engine.ConfigKeys, ", "))
+		}
+	}
+	if configured == 0 {
+		return fmt.Errorf("请至少配置一个搜索引擎的 API Key")
+	}
+	return nil
+}
+
+// NewScanners 创建所选引擎的全部扫描器
+func NewScanners(selected []Engine, cfg *config.Config) []Scanner {
+	var scanners []Scanner
+	for _, engine := range selected {
+		scanners = append(scanners, engine.New(cfg)...)
+	}
+	return scanners
+}
+
+// IsConfigured 配置中是否提供了引擎需要的 Key
+func (e Engine) IsConfigured(cfg *config.Config) bool {
+	return e.Configured == nil || e.Configured(cfg)
+}
+
+// buildQuery 使用扫描器注册的查询构造函数生成查询语句，原生查询原样返回
+func buildQuery(scannerName string, target Target) string {
+	if target.Type == "query" {
+		return target.Value
+	}
+
+	registryMu.RLock()
+	builder, ok := queryBuilders[scannerName]
+	registryMu.RUnlock()
+
+	if !ok {
+		return ""
+	}
+	return builder(target)
+}
@@ -0,0 +1,172 @@
+package cse
+
+import (
+	"cscan/internal/common/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 测试使用的引擎，alphatest 需要 Hunter Key，betatest 需要 FOFA 账号
+func init() {
+	Register(Engine{
+		Name:       "AlphaTest",
+		ConfigKeys: []string{"hunter_api_key"},
+		Configured: func(cfg *config.Config) bool { return len(cfg.HunterKeys()) > 0 },
+		New:        func(cfg *config.Config) []Scanner { return []Scanner{namedScanner("Alpha")} },
+		Queries: map[string]QueryBuilder{
+			"Alpha": func(t Target) string { return "alpha:" + t.Value },
+		},
+	})
+	Register(Engine{
+		Name:       "betatest",
+		ConfigKeys: []string{"fofa_email", "fofa_api_key"},
+		Configured: func(cfg *config.Config) bool { return len(cfg.FofaKeys()) > 0 },
+		New: func(cfg *config.Config) []Scanner {
+			return []Scanner{namedScanner("Beta-Host"), namedScanner("Beta-Web")}
+		},
+		Queries: map[string]QueryBuilder{
+			"Beta-Host": func(t Target) string { return "host:" + t.Value },
+			"Beta-Web":  func(t Target) string { return "web:" + t.Value },
+		},
+	})
+}
+
+func TestRegister(t *testing.T) {
+	engine, ok := LookupEngine(" ALPHATEST ")
+	if !ok || engine.Name != "alphatest" {
+		t.Fatalf("LookupEngine() = %+v, %v, want alphatest", engine, ok)
+	}
+	if _, ok := LookupEngine("missing"); ok {
+		t.Error("LookupEngine() 找到了未注册的引擎")
+	}
+	if got := EngineOf("Beta-Web"); got != "betatest" {
+		t.Errorf("EngineOf(Beta-Web) = %q, want betatest", got)
+	}
+	if got := EngineOf("Unknown"); got != "" {
+		t.Errorf("EngineOf(Unknown) = %q, want empty", got)
+	}
+
+	scanners := NewScanners([]Engine{engine, mustLookup(t, "betatest")}, &config.Config{})
+	if len(scanners) != 3 || scanners[0].Name() != "Alpha" || scanners[2].Name() != "Beta-Web" {
+		t.Errorf("NewScanners() = %v", scanners)
+	}
+
+	tests := []struct {
+		name   string
+		target Target
+		want   string
+	}{
+		{"Alpha", Target{Value: "1.1.1.1", Type: "ip"}, "alpha:1.1.1.1"},
+		{"Beta-Web", Target{Value: "example.com", Type: "domain"}, "web:example.com"},
+		{"Beta-Host", Target{Value: `title="x"`, Type: "query"}, `title="x"`},
+		{"Unknown", Target{Value: "1.1.1.1", Type: "ip"}, ""},
+	}
+	for _, tt := range tests {
+		if got := buildQuery(tt.name, tt.target); got != tt.want {
+			t.Errorf("buildQuery(%s, %+v) = %q, want %q", tt.name, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine Engine
+	}{
+		{"重复的名称", Engine{Name: "AlphaTest", New: func(cfg *config.Config) []Scanner { return nil }}},
+		{"缺少名称", Engine{New: func(cfg *config.Config) []Scanner { return nil }}},
+		{"缺少构造函数", Engine{Name: "noconstructor"}},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Register 没有 panic", tt.name)
+				}
+			}()
+			Register(tt.engine)
+		}()
+	}
+}
+
+// mustLookup 查找已注册的引擎
+func mustLookup(t *testing.T, name string) Engine {
+	t.Helper()
+	engine, ok := LookupEngine(name)
+	if !ok {
+		t.Fatalf("引擎 %s 未注册", name)
+	}
+	return engine
+}
+
+func TestSelectEngines(t *testing.T) {
+	// Hunter Key 仍为默认占位值，只有 FOFA 已配置
+	cfg := &config.Config{HunterAPIKey: "your-hunter-key", FofaEmail: "user@example.com", FofaAPIKey: "key"}
+
+	tests := []struct {
+		list string
+		want []string
+	}{
+		// 未指定时按名称排序返回所有已配置的引擎，跳过占位 Key 的引擎
+		{"", []string{"betatest", "sharedtest"}},
+		{"  ", []string{"betatest", "sharedtest"}},
+		// 显式指定时保持参数中的顺序，忽略重复和空项，不检查配置
+		{"betatest,alphatest", []string{"betatest", "alphatest"}},
+		{" AlphaTest , ,betatest,ALPHATEST", []string{"alphatest", "betatest"}},
+	}
+	for _, tt := range tests {
+		selected, err := SelectEngines(tt.list, cfg)
+		if err != nil {
+			t.Errorf("SelectEngines(%q) error: %v", tt.list, err)
+			continue
+		}
+		var got []string
+		for _, engine := range selected {
+			// 忽略其他测试注册的引擎
+			if engine.Name == "alphatest" || engine.Name == "betatest" || engine.Name == "sharedtest" {
+				got = append(got, engine.Name)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SelectEngines(%q) = %v, want %v", tt.list, got, tt.want)
+		}
+	}
+
+	_, err := SelectEngines("betatest,nosuchengine", cfg)
+	if err == nil || !strings.Contains(err.Error(), "未知的子模块: nosuchengine") || !strings.Contains(err.Error(), "alphatest") {
+		t.Errorf("SelectEngines() error = %v, want unknown engine with available list", err)
+	}
+}
+
+func TestValidateEngines(t *testing.T) {
+	alpha, beta := mustLookup(t, "alphatest"), mustLookup(t, "betatest")
+	onlyFofa := &config.Config{HunterAPIKey: "your-hunter-key", FofaEmail: "user@example.com", FofaAPIKey: "key"}
+	none := &config.Config{HunterAPIKey: "your-hunter-key", FofaAPIKey: "your-fofa-key"}
+
+	tests := []struct {
+		name     string
+		selected []Engine
+		cfg      *config.Config
+		explicit bool
+		want     string // 错误信息应包含的内容，为空表示通过
+	}{
+		{"默认引擎中有已配置的引擎", []Engine{alpha, beta}, onlyFofa, false, ""},
+		{"显式选择的引擎均已配置", []Engine{beta}, onlyFofa, true, ""},
+		{"显式选择了占位 Key 的引擎", []Engine{beta, alpha}, onlyFofa, true, "引擎 alphatest 未配置，请在配置文件中设置 hunter_api_key"},
+		{"没有已配置的引擎", []Engine{alpha, beta}, none, false, "请至少配置一个"},
+		{"没有选择引擎", nil, onlyFofa, false, "请至少配置一个"},
+	}
+	for _, tt := range tests {
+		err := ValidateEngines(tt.selected, tt.cfg, tt.explicit)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cse
+
+import (
+	cerrors "cscan/internal/common/errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 单页请求失败后的重试策略
+type RetryPolicy struct {
+	MaxAttempts int           // 每页最多尝试次数（含首次请求）
+	BaseDelay   time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay    time.Duration // 单次等待上限
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    MaxRetryWait,
+}
+
+// withDefaults 用默认值补全未设置的字段
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// next 判断第 attempt 次请求失败后是否重试，并返回重试前的等待时间
+// 只重试速率限制、网络错误等临时错误；服务端通过 Retry-After 指定的等待时间优先，
+// 超过 MaxDelay 时不再重试
+func (p RetryPolicy) next(attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || !cerrors.IsTransient(err) {
+		return 0, false
+	}
+
+	if retryAfter := cerrors.RetryAfterOf(err); retryAfter > 0 {
+		if retryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return retryAfter, true
+	}
+
+	// 指数退避加随机抖动，抖动范围为退避时间的一半
+	backoff := p.BaseDelay << uint(attempt-1)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	delay := backoff/2 + jitter
+	if delay < p.BaseDelay {
+		delay = p.BaseDelay
+	}
+	return delay, true
+}
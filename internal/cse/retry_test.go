@@ -0,0 +1,70 @@
+package cse
+
+import (
+	cerrors "cscan/internal/common/errors"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyNext(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 15 * time.Second}
+	network := cerrors.New(cerrors.KindNetwork, "Test", "502 Bad Gateway")
+	rateLimited := func(retryAfter time.Duration) error {
+		return &cerrors.ScanError{Kind: cerrors.KindRateLimited, Source: "Test", RetryAfter: retryAfter}
+	}
+
+	tests := []struct {
+		name     string
+		attempt  int
+		err      error
+		retry    bool
+		min, max time.Duration // 抖动后的等待时间范围
+	}{
+		// 退避时间为 BaseDelay << (attempt-1)，实际等待在其一半到全部之间，且不少于 BaseDelay
+		{"第 1 次重试", 1, network, true, time.Second, time.Second},
+		{"第 2 次重试", 2, network, true, time.Second, 2 * time.Second},
+		{"第 3 次重试", 3, rateLimited(0), true, 2 * time.Second, 4 * time.Second},
+		{"第 4 次重试", 4, network, true, 4 * time.Second, 8 * time.Second},
+		{"达到最多尝试次数", 5, network, false, 0, 0},
+		{"使用 Retry-After", 1, rateLimited(7 * time.Second), true, 7 * time.Second, 7 * time.Second},
+		{"Retry-After 等于 MaxDelay", 1, rateLimited(15 * time.Second), true, 15 * time.Second, 15 * time.Second},
+		{"Retry-After 超过 MaxDelay 时放弃", 1, rateLimited(time.Minute), false, 0, 0},
+		{"额度耗尽", 1, cerrors.New(cerrors.KindQuotaExhausted, "Test", "积分不足"), false, 0, 0},
+		{"鉴权失败", 1, cerrors.New(cerrors.KindAuthFailed, "Test", "invalid api key"), false, 0, 0},
+		{"查询语句错误", 1, cerrors.New(cerrors.KindBadQuery, "Test", "语法错误"), false, 0, 0},
+		{"无法解析的响应", 1, cerrors.New(cerrors.KindMalformedResponse, "Test", "not json"), false, 0, 0},
+		{"未分类的错误", 1, errors.New("boom"), false, 0, 0},
+	}
+	for _, tt := range tests {
+		// 抖动是随机的，多次检查等待时间的范围
+		for i := 0; i < 50; i++ {
+			delay, retry := policy.next(tt.attempt, tt.err)
+			if retry != tt.retry {
+				t.Errorf("%s: retry = %v, want %v", tt.name, retry, tt.retry)
+				break
+			}
+			if delay < tt.min || delay > tt.max {
+				t.Errorf("%s: delay = %v, want between %v and %v", tt.name, delay, tt.min, tt.max)
+				break
+			}
+		}
+	}
+
+	// 退避时间超过 MaxDelay 时按 MaxDelay 计算
+	capped := RetryPolicy{MaxAttempts: 10, BaseDelay: 4 * time.Second, MaxDelay: 10 * time.Second}
+	for attempt := 3; attempt < 10; attempt++ {
+		delay, retry := capped.next(attempt, network)
+		if !retry || delay < 5*time.Second || delay > 10*time.Second {
+			t.Errorf("attempt %d: next() = %v, %v, want 5s-10s", attempt, delay, retry)
+		}
+	}
+}
+
+func TestRetryPolicyWithDefaults(t *testing.T) {
+	got := RetryPolicy{MaxAttempts: 2}.withDefaults()
+	want := RetryPolicy{MaxAttempts: 2, BaseDelay: DefaultRetryPolicy.BaseDelay, MaxDelay: DefaultRetryPolicy.MaxDelay}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package shodan
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	cse.Register(cse.Engine{
+		Name:        "shodan",
+		Description: "Shodan 引擎，适合境外目标",
+		ConfigKeys:  []string{"shodan_api_key", "shodan_api_keys"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.ShodanKeys()) > 0
+		},
+		New: func(cfg *config.Config) []cse.Scanner {
+			return []cse.Scanner{newFromConfig(cfg)}
+		},
+		Queries: map[string]cse.QueryBuilder{
+			"Shodan": buildQuery,
+		},
+	})
+}
+
+// buildQuery 将目标转换为 Shodan 查询语句
+// Shodan 不支持 OR，IP 范围拆分为网段后使用 net 过滤器的逗号分隔形式
+func buildQuery(target cse.Target) string {
+	switch target.Type {
+	case "ip":
+		return fmt.Sprintf(`ip:%s`, target.Value)
+	case "ipv6":
+		return fmt.Sprintf(`ip:"%s"`, target.Value)
+	case "cidr":
+		return fmt.Sprintf(`net:%s`, target.Value)
+	case "range":
+		cidrs, err := cse.RangeCIDRs(target.Value)
+		if err != nil {
+			return fmt.Sprintf(`net:%s`, target.Value)
+		}
+		return "net:" + strings.Join(cidrs, ",")
+	case "asn":
+		return fmt.Sprintf(`asn:AS%s`, target.Value)
+	}
+	// hostname 过滤器按后缀匹配，域名和完整主机名使用相同的语法
+	return fmt.Sprintf(`hostname:%s`, target.Value)
+}
+
+// newFromConfig 根据配置创建扫描器，配置了多个 Key 时返回账号池
+func newFromConfig(cfg *config.Config) cse.Scanner {
+	opts := cfg.HTTPOptions("shodan")
+	keys := cfg.ShodanKeys()
+	switch len(keys) {
+	case 0:
+		return NewScanner(cfg.ShodanAPIKey, opts...)
+	case 1:
+		return NewScanner(keys[0], opts...)
+	}
+	var members []cse.PoolMember
+	for _, key := range keys {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(key), Scanner: NewScanner(key, opts...)})
+	}
+	return cse.NewKeyPool(members...)
+}
@@ -0,0 +1,198 @@
+package shodan
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"cscan/internal/common/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// DefaultBaseURL Shodan API 默认地址
+const DefaultBaseURL = "https://api.shodan.io"
+
+type Scanner struct {
+	httpopt.Client
+	apiKey string
+}
+
+func NewScanner(apiKey string, opts ...httpopt.Option) *Scanner {
+	return &Scanner{
+		Client: httpopt.New(DefaultBaseURL, opts...),
+		apiKey: apiKey,
+	}
+}
+
+func (s *Scanner) Name() string {
+	return "Shodan"
+}
+
+// Search 调用 host search 接口
+// Shodan 每页固定返回 100 条，size 参数不影响请求
+func (s *Scanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	body, err := s.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := s.Parse(body)
+	return assets, err
+}
+
+// Fetch 请求一页搜索结果并检查接口错误，返回原始响应体，Shodan 按页码分页，忽略 cursor
+func (s *Scanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
+	params := url.Values{}
+	params.Add("key", s.apiKey)
+	params.Add("query", query)
+	params.Add("page", fmt.Sprintf("%d", page))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/shodan/host/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var result struct {
+		Error string `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	if result.Error != "" {
+		return nil, cerrors.FromAPI(s.Name(), resp, result.Error)
+	}
+	if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+		return nil, se
+	}
+
+	return body, nil
+}
+
+// Parse 将 Fetch 返回的响应体解析为资产列表
+func (s *Scanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var result struct {
+		Matches []struct {
+			IPStr     string   `json:"ip_str"`
+			Port      int      `json:"port"`
+			Transport string   `json:"transport"`
+			Hostnames []string `json:"hostnames"`
+			Domains   []string `json:"domains"`
+			Org       string   `json:"org"`
+			Shodan    struct {
+				Module string `json:"module"`
+			} `json:"_shodan"`
+			HTTP *struct {
+				Title  string `json:"title"`
+				Status int    `json:"status"`
+			} `json:"http"`
+			Location struct {
+				CountryName string `json:"country_name"`
+				RegionCode  string `json:"region_code"`
+				City        string `json:"city"`
+			} `json:"location"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	var assets []model.Asset
+	for _, item := range result.Matches {
+		asset := model.Asset{
+			IP:     item.IPStr,
+			Port:   fmt.Sprintf("%d", item.Port),
+			Org:    item.Org, // Shodan 的 org 是 IP 所属的网络组织，没有 ICP 备案信息
+			Source: s.Name(),
+		}
+
+		// 优先使用 hostname，其次是注册域名
+		if len(item.Hostnames) > 0 {
+			asset.Domain = item.Hostnames[0]
+		} else if len(item.Domains) > 0 {
+			asset.Domain = item.Domains[0]
+		}
+
+		// 服务名使用 Shodan 识别的协议模块，缺失时退回传输层协议
+		asset.Service = item.Shodan.Module
+		if asset.Service == "" {
+			asset.Service = item.Transport
+		}
+
+		if item.HTTP != nil {
+			asset.Title = item.HTTP.Title
+			if item.HTTP.Status > 0 {
+				asset.StatusCode = fmt.Sprintf("%d", item.HTTP.Status)
+			}
+		}
+
+		asset.Location = model.JoinLocation(item.Location.CountryName, item.Location.RegionCode, item.Location.City)
+
+		assets = append(assets, asset)
+	}
+
+	return assets, "", nil
+}
+
+// Quota 查询账户剩余的查询积分
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	params := url.Values{}
+	params.Add("key", s.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+"/api-info?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var result struct {
+		Error        string `json:"error"`
+		QueryCredits int    `json:"query_credits"`
+		ScanCredits  int    `json:"scan_credits"`
+		Plan         string `json:"plan"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	if result.Error != "" {
+		return nil, cerrors.FromAPI(s.Name(), resp, result.Error)
+	}
+
+	return &model.Quota{
+		Remaining: result.QueryCredits,
+		Unit:      "积分",
+		Detail:    fmt.Sprintf("套餐 %s", result.Plan),
+	}, nil
+}
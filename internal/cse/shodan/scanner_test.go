@@ -0,0 +1,110 @@
+package shodan
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器，handler 返回固定响应
+func newTestScanner(t *testing.T, status int, body string) (*Scanner, *http.Request) {
+	t.Helper()
+	var got http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewScanner("secret", httpopt.WithBaseURL(server.URL)), &got
+}
+
+func TestSearch(t *testing.T) {
+	s, req := newTestScanner(t, http.StatusOK, `{
+		"total": 1,
+		"matches": [{
+			"ip_str": "93.184.216.34",
+			"port": 443,
+			"transport": "tcp",
+			"hostnames": ["www.example.com"],
+			"domains": ["example.com"],
+			"org": "Edgecast Inc.",
+			"_shodan": {"module": "https"},
+			"http": {"title": "Example Domain", "status": 200},
+			"location": {"country_name": "United States", "region_code": "MA", "city": "Norwell"}
+		}]
+	}`)
+
+	assets, err := s.Search(context.Background(), "hostname:example.com", 2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.URL.Path; got != "/shodan/host/search" {
+		t.Errorf("path = %s", got)
+	}
+	if q := req.URL.Query(); q.Get("key") != "secret" || q.Get("query") != "hostname:example.com" || q.Get("page") != "2" {
+		t.Errorf("query = %v", q)
+	}
+
+	if len(assets) != 1 {
+		t.Fatalf("len(assets) = %d, want 1", len(assets))
+	}
+	a := assets[0]
+	checks := []struct{ name, got, want string }{
+		{"IP", a.IP, "93.184.216.34"},
+		{"Port", a.Port, "443"},
+		{"Domain", a.Domain, "www.example.com"},
+		{"Service", a.Service, "https"},
+		{"Title", a.Title, "Example Domain"},
+		{"StatusCode", a.StatusCode, "200"},
+		{"Org", a.Org, "Edgecast Inc."},
+		{"ICPOrg", a.ICPOrg, ""},
+		{"Location", a.Location, "United States MA Norwell"},
+		{"Source", a.Source, "Shodan"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   cerrors.Kind
+	}{
+		{"无效的 Key", http.StatusUnauthorized, `{"error": "Please provide a valid API key."}`, cerrors.KindAuthFailed},
+		{"速率限制", http.StatusTooManyRequests, `{"error": "Rate limit reached."}`, cerrors.KindRateLimited},
+		{"查询错误", http.StatusBadRequest, `{"error": "Invalid search query"}`, cerrors.KindBadQuery},
+		{"额度耗尽", http.StatusPaymentRequired, `{"error": "Insufficient query credits"}`, cerrors.KindQuotaExhausted},
+		{"服务端错误页面", http.StatusBadGateway, `<html>Bad Gateway</html>`, cerrors.KindNetwork},
+		{"无法解析的响应", http.StatusOK, `not json`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s, _ := newTestScanner(t, tt.status, tt.body)
+		_, err := s.Search(context.Background(), "port:22", 1, 100)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s, req := newTestScanner(t, http.StatusOK, `{"query_credits": 87, "scan_credits": 10, "plan": "dev"}`)
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/api-info" {
+		t.Errorf("path = %s", req.URL.Path)
+	}
+	if quota.Remaining != 87 {
+		t.Errorf("Remaining = %d, want 87", quota.Remaining)
+	}
+}
@@ -0,0 +1,87 @@
+package cse
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParseRange 解析 "起始IP-结束IP" 格式的 IP 范围
+// 支持简写的 IPv4 范围，如 1.1.1.1-50 表示 1.1.1.1-1.1.1.50
+func ParseRange(value string) (netip.Addr, netip.Addr, error) {
+	startText, endText, ok := strings.Cut(value, "-")
+	if !ok {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("无效的 IP 范围: %s", value)
+	}
+	startText, endText = strings.TrimSpace(startText), strings.TrimSpace(endText)
+
+	start, err := netip.ParseAddr(startText)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("无效的 IP 范围: %s", value)
+	}
+
+	// 简写形式只替换最后一段
+	if start.Is4() && !strings.Contains(endText, ".") {
+		if i := strings.LastIndex(startText, "."); i > 0 {
+			endText = startText[:i+1] + endText
+		}
+	}
+	end, err := netip.ParseAddr(endText)
+	if err != nil || end.BitLen() != start.BitLen() || end.Less(start) {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("无效的 IP 范围: %s", value)
+	}
+	return start, end, nil
+}
+
+// RangeCIDRs 将 IP 范围拆分为最少数量的 CIDR 网段，供不支持范围查询的引擎使用
+func RangeCIDRs(value string) ([]string, error) {
+	start, end, err := ParseRange(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cidrs []string
+	for {
+		// 找到以 start 开头且不超过 end 的最大网段
+		prefix := netip.PrefixFrom(start, start.BitLen())
+		for bits := 0; bits <= start.BitLen(); bits++ {
+			p := netip.PrefixFrom(start, bits).Masked()
+			if p.Addr() == start && !end.Less(lastAddr(p)) {
+				prefix = p
+				break
+			}
+		}
+		cidrs = append(cidrs, prefix.String())
+
+		last := lastAddr(prefix)
+		if last == end {
+			break
+		}
+		start = last.Next()
+	}
+	return cidrs, nil
+}
+
+// lastAddr 返回网段中的最后一个地址
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - uint(i%8))
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// ExpandRange 将 IP 范围展开为多个网段条件，用 sep 连接
+// 范围无效时原样使用
+func ExpandRange(value string, term func(cidr string) string, sep string) string {
+	cidrs, err := RangeCIDRs(value)
+	if err != nil {
+		return term(value)
+	}
+	var terms []string
+	for _, cidr := range cidrs {
+		terms = append(terms, term(cidr))
+	}
+	return strings.Join(terms, sep)
+}
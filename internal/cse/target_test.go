@@ -0,0 +1,90 @@
+package cse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value      string
+		start, end string
+		wantErr    bool
+	}{
+		{value: "1.1.1.1-1.1.1.50", start: "1.1.1.1", end: "1.1.1.50"},
+		{value: "1.1.1.1-50", start: "1.1.1.1", end: "1.1.1.50"},
+		{value: " 10.0.0.0 - 10.0.255.255 ", start: "10.0.0.0", end: "10.0.255.255"},
+		{value: "1.1.1.5-1.1.1.5", start: "1.1.1.5", end: "1.1.1.5"},
+		{value: "2001:db8::1-2001:db8::ff", start: "2001:db8::1", end: "2001:db8::ff"},
+		{value: "1.1.1.1", wantErr: true},
+		{value: "1.1.1.50-1.1.1.1", wantErr: true},
+		{value: "1.1.1.50-1", wantErr: true},
+		{value: "1.1.1.1-256", wantErr: true},
+		{value: "1.1.1.1-2001:db8::1", wantErr: true},
+		{value: "2001:db8::1-ff", wantErr: true},
+		{value: "a-b", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseRange(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRange(%q) = %s, %s, want error", tt.value, start, end)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRange(%q) error: %v", tt.value, err)
+			continue
+		}
+		if start.String() != tt.start || end.String() != tt.end {
+			t.Errorf("ParseRange(%q) = %s, %s, want %s, %s", tt.value, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestRangeCIDRs(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"1.1.1.5-1.1.1.5", []string{"1.1.1.5/32"}},
+		{"10.0.0.0-10.0.0.255", []string{"10.0.0.0/24"}},
+		{"10.0.0.0-10.0.255.255", []string{"10.0.0.0/16"}},
+		{"1.1.1.1-10", []string{"1.1.1.1/32", "1.1.1.2/31", "1.1.1.4/30", "1.1.1.8/31", "1.1.1.10/32"}},
+		{"192.168.0.255-192.168.1.0", []string{"192.168.0.255/32", "192.168.1.0/32"}},
+		{"0.0.0.0-255.255.255.255", []string{"0.0.0.0/0"}},
+		{"255.255.255.254-255.255.255.255", []string{"255.255.255.254/31"}},
+		{"2001:db8::-2001:db8::ffff", []string{"2001:db8::/112"}},
+		{"2001:db8::1-2001:db8::3", []string{"2001:db8::1/128", "2001:db8::2/127"}},
+	}
+	for _, tt := range tests {
+		got, err := RangeCIDRs(tt.value)
+		if err != nil {
+			t.Errorf("RangeCIDRs(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RangeCIDRs(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := RangeCIDRs("1.1.1.9-1.1.1.1"); err == nil {
+		t.Error("RangeCIDRs 应拒绝结束地址小于起始地址的范围")
+	}
+}
+
+func TestExpandRange(t *testing.T) {
+	term := func(cidr string) string { return `ip="` + cidr + `"` }
+	tests := []struct {
+		value, want string
+	}{
+		{"1.1.1.0-1.1.1.1", `ip="1.1.1.0/31"`},
+		{"1.1.1.1-2", `ip="1.1.1.1/32" || ip="1.1.1.2/32"`},
+		{"not-a-range", `ip="not-a-range"`},
+	}
+	for _, tt := range tests {
+		if got := ExpandRange(tt.value, term, " || "); got != tt.want {
+			t.Errorf("ExpandRange(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package zoomeye
+
+import (
+	"cscan/internal/common/config"
+	"cscan/internal/cse"
+	"fmt"
+)
+
+func init() {
+	cse.Register(cse.Engine{
+		Name:        "zoomeye",
+		Description: "ZoomEye 引擎，同时进行主机搜索和 Web 搜索",
+		ConfigKeys:  []string{"zoomeye_api_key", "zoomeye_api_keys"},
+		Configured: func(cfg *config.Config) bool {
+			return len(cfg.ZoomEyeKeys()) > 0
+		},
+		New: func(cfg *config.Config) []cse.Scanner {
+			return []cse.Scanner{
+				newFromConfig(cfg, ResourceHost),
+				newFromConfig(cfg, ResourceWeb),
+			}
+		},
+		Queries: map[string]cse.QueryBuilder{
+			"ZoomEye":     buildHostQuery,
+			"ZoomEye-Web": buildWebQuery,
+		},
+	})
+}
+
+// buildHostQuery 将目标转换为主机搜索的查询语句
+func buildHostQuery(target cse.Target) string {
+	if q, ok := buildIPQuery(target); ok {
+		return q
+	}
+	return fmt.Sprintf(`hostname:"%s"`, target.Value)
+}
+
+// buildWebQuery 将目标转换为 Web 搜索的查询语句
+func buildWebQuery(target cse.Target) string {
+	if q, ok := buildIPQuery(target); ok {
+		return q
+	}
+	return fmt.Sprintf(`site:"%s"`, target.Value)
+}
+
+// buildIPQuery 转换 IP 类目标，ZoomEye 不支持 IP 范围，范围会被拆分为多个网段
+func buildIPQuery(target cse.Target) (string, bool) {
+	switch target.Type {
+	case "ip", "ipv6":
+		return fmt.Sprintf(`ip:"%s"`, target.Value), true
+	case "cidr":
+		return fmt.Sprintf(`cidr:"%s"`, target.Value), true
+	case "range":
+		return cse.ExpandRange(target.Value, func(cidr string) string {
+			return fmt.Sprintf(`cidr:"%s"`, cidr)
+		}, " || "), true
+	case "asn":
+		return fmt.Sprintf(`asn:"%s"`, target.Value), true
+	}
+	return "", false
+}
+
+// newFromConfig 根据配置创建指定资源的扫描器，配置了多个 Key 时返回账号池
+// 主机搜索和 Web 搜索各自使用一个账号池，分别记录各 Key 的使用情况
+func newFromConfig(cfg *config.Config, resource string) cse.Scanner {
+	opts := cfg.HTTPOptions("zoomeye")
+	keys := cfg.ZoomEyeKeys()
+	switch len(keys) {
+	case 0:
+		return NewScanner(cfg.ZoomEyeKey, resource, opts...)
+	case 1:
+		return NewScanner(keys[0], resource, opts...)
+	}
+	var members []cse.PoolMember
+	for _, key := range keys {
+		members = append(members, cse.PoolMember{Label: cse.MaskKey(key), Scanner: NewScanner(key, resource, opts...)})
+	}
+	return cse.NewKeyPool(members...)
+}
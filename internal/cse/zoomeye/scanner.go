@@ -0,0 +1,283 @@
+package zoomeye
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"cscan/internal/common/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// DefaultBaseURL ZoomEye API 默认地址
+const DefaultBaseURL = "https://api.zoomeye.org"
+
+// 搜索资源类型
+const (
+	ResourceHost = "host" // 主机设备搜索
+	ResourceWeb  = "web"  // Web 应用搜索
+)
+
+type Scanner struct {
+	httpopt.Client
+	apiKey   string
+	resource string
+}
+
+// NewScanner 创建扫描器，resource 为 ResourceWeb 时使用 Web 应用搜索，其他值均为主机搜索
+func NewScanner(apiKey, resource string, opts ...httpopt.Option) *Scanner {
+	if resource != ResourceWeb {
+		resource = ResourceHost
+	}
+	return &Scanner{
+		Client:   httpopt.New(DefaultBaseURL, opts...),
+		apiKey:   apiKey,
+		resource: resource,
+	}
+}
+
+// Name 主机搜索和 Web 搜索使用不同的名称，便于区分结果来源和速率限制；
+// 两者属于同一账号，额度、重试策略和额度检查按引擎名 zoomeye 共享
+func (s *Scanner) Name() string {
+	if s.resource == ResourceWeb {
+		return "ZoomEye-Web"
+	}
+	return "ZoomEye"
+}
+
+// Search 搜索资产
+// ZoomEye 每页固定返回 20 条结果，size 参数不生效
+func (s *Scanner) Search(ctx context.Context, query string, page, size int) ([]model.Asset, error) {
+	body, err := s.Fetch(ctx, query, page, "", size)
+	if err != nil {
+		return nil, err
+	}
+	assets, _, err := s.Parse(body)
+	return assets, err
+}
+
+// Fetch 请求一页搜索结果并检查接口错误，返回原始响应体，ZoomEye 按页码分页，忽略 cursor
+func (s *Scanner) Fetch(ctx context.Context, query string, page int, cursor string, size int) ([]byte, error) {
+	params := url.Values{}
+	params.Add("query", query)
+	params.Add("page", fmt.Sprintf("%d", page))
+	return s.get(ctx, "/"+s.resource+"/search?"+params.Encode())
+}
+
+// Parse 将 Fetch 返回的响应体解析为资产列表
+func (s *Scanner) Parse(body []byte) ([]model.Asset, string, error) {
+	var result struct {
+		Matches []map[string]interface{} `json:"matches"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	var assets []model.Asset
+	for _, item := range result.Matches {
+		var asset model.Asset
+		if s.resource == ResourceWeb {
+			asset = parseWeb(item)
+		} else {
+			asset = parseHost(item)
+		}
+		asset.Source = s.Name()
+
+		if geo, ok := item["geoinfo"].(map[string]interface{}); ok {
+			asset.Location = parseLocation(geo)
+		}
+		asset.ICPOrg = parseICP(item)
+
+		assets = append(assets, asset)
+	}
+
+	return assets, "", nil
+}
+
+// Quota 查询账户剩余的查询额度
+func (s *Scanner) Quota(ctx context.Context) (*model.Quota, error) {
+	var result struct {
+		Plan      string `json:"plan"`
+		QuotaInfo struct {
+			RemainFreeQuota  int `json:"remain_free_quota"`
+			RemainPayQuota   int `json:"remain_pay_quota"`
+			RemainTotalQuota int `json:"remain_total_quota"`
+		} `json:"quota_info"`
+	}
+	body, err := s.get(ctx, "/resources-info")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	return &model.Quota{
+		Remaining: result.QuotaInfo.RemainTotalQuota,
+		Unit:      "条",
+		PerResult: true,
+		Detail: fmt.Sprintf("免费 %d 条，付费 %d 条",
+			result.QuotaInfo.RemainFreeQuota, result.QuotaInfo.RemainPayQuota),
+	}, nil
+}
+
+// get 发起请求并检查接口错误，返回响应体，ZoomEye 出错时返回 {"error": "...", "message": "..."}
+func (s *Scanner) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("API-KEY", s.apiKey)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, cerrors.Wrap(cerrors.KindNetwork, s.Name(), err)
+	}
+
+	var apiErr struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+			return nil, se
+		}
+		return nil, cerrors.Wrap(cerrors.KindMalformedResponse, s.Name(), err)
+	}
+
+	if apiErr.Error != "" {
+		message := apiErr.Message
+		if message == "" {
+			message = apiErr.Error
+		}
+		se := cerrors.FromAPI(s.Name(), resp, message)
+		switch apiErr.Error {
+		case "login_required", "invalid_token", "invalid_api_key", "forbidden":
+			se.Kind = cerrors.KindAuthFailed
+		case "credits_insufficent", "credits_insufficient", "resource_insufficient":
+			se.Kind = cerrors.KindQuotaExhausted
+		case "request_limit", "request_too_fast":
+			se.Kind = cerrors.KindRateLimited
+		case "bad_request", "query_invalid":
+			se.Kind = cerrors.KindBadQuery
+		}
+		return nil, se
+	}
+	if se := cerrors.FromResponse(s.Name(), resp); se != nil {
+		return nil, se
+	}
+
+	return body, nil
+}
+
+// parseHost 解析主机搜索结果
+func parseHost(item map[string]interface{}) model.Asset {
+	var asset model.Asset
+
+	if v, ok := item["ip"].(string); ok {
+		asset.IP = v
+	}
+	if v, ok := item["rdns"].(string); ok {
+		asset.Domain = v
+	}
+
+	if portinfo, ok := item["portinfo"].(map[string]interface{}); ok {
+		if v, ok := portinfo["port"].(float64); ok {
+			asset.Port = fmt.Sprintf("%d", int(v))
+		}
+		if v, ok := portinfo["service"].(string); ok {
+			asset.Service = v
+		}
+		if v, ok := portinfo["hostname"].(string); ok && v != "" {
+			asset.Domain = v
+		}
+		asset.Title = firstString(portinfo["title"])
+	}
+
+	return asset
+}
+
+// parseWeb 解析 Web 搜索结果，端口从站点地址中提取
+func parseWeb(item map[string]interface{}) model.Asset {
+	var asset model.Asset
+
+	asset.IP = firstString(item["ip"])
+	asset.Title = firstString(item["title"])
+
+	if site, ok := item["site"].(string); ok {
+		asset.Domain = site
+		if host, port, err := net.SplitHostPort(site); err == nil {
+			asset.Domain = host
+			asset.Port = port
+		}
+	}
+
+	if v, ok := item["service"].(string); ok {
+		asset.Service = v
+	} else {
+		asset.Service = "http"
+	}
+
+	return asset
+}
+
+// parseLocation 拼接地理位置，优先使用中文名称
+func parseLocation(geo map[string]interface{}) string {
+	var location []string
+	for _, key := range []string{"country", "subdivisions", "city"} {
+		part, ok := geo[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names, ok := part["names"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := names["zh-CN"].(string); ok && v != "" {
+			location = append(location, v)
+		} else if v, ok := names["en"].(string); ok && v != "" {
+			location = append(location, v)
+		}
+	}
+	return model.JoinLocation(location...)
+}
+
+// parseICP 提取 ICP 备案主体，部分结果中为字符串，部分为对象
+func parseICP(item map[string]interface{}) string {
+	switch icp := item["icp"].(type) {
+	case string:
+		return icp
+	case map[string]interface{}:
+		for _, key := range []string{"name", "organization", "company"} {
+			if v, ok := icp[key].(string); ok && v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// firstString 兼容字符串和字符串数组两种字段格式
+func firstString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package zoomeye
+
+import (
+	"context"
+	cerrors "cscan/internal/common/errors"
+	"cscan/internal/common/httpopt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestScanner 创建指向测试服务器的扫描器
+func newTestScanner(t *testing.T, resource string, handler http.HandlerFunc) *Scanner {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewScanner("secret", resource, httpopt.WithBaseURL(server.URL))
+}
+
+func TestSearchHost(t *testing.T) {
+	s := newTestScanner(t, ResourceHost, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/host/search" || r.Header.Get("API-KEY") != "secret" {
+			t.Errorf("path = %s, API-KEY = %q", r.URL.Path, r.Header.Get("API-KEY"))
+		}
+		if r.URL.Query().Get("page") != "3" {
+			t.Errorf("page = %s, want 3", r.URL.Query().Get("page"))
+		}
+		w.Write([]byte(`{
+			"total": 1,
+			"matches": [{
+				"ip": "1.2.3.4",
+				"rdns": "rdns.example.com",
+				"portinfo": {"port": 8080, "service": "http", "hostname": "www.example.com", "title": ["首页"]},
+				"geoinfo": {
+					"country": {"names": {"zh-CN": "中国", "en": "China"}},
+					"subdivisions": {"names": {"en": "Zhejiang"}},
+					"city": {"names": {"zh-CN": "杭州"}}
+				},
+				"icp": {"name": "示例公司"}
+			}]
+		}`))
+	})
+
+	assets, err := s.Search(context.Background(), `ip:"1.2.3.4"`, 3, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 {
+		t.Fatalf("len(assets) = %d, want 1", len(assets))
+	}
+	a := assets[0]
+	checks := []struct{ name, got, want string }{
+		{"IP", a.IP, "1.2.3.4"},
+		{"Port", a.Port, "8080"},
+		{"Domain", a.Domain, "www.example.com"},
+		{"Service", a.Service, "http"},
+		{"Title", a.Title, "首页"},
+		{"Location", a.Location, "中国 Zhejiang 杭州"},
+		{"ICPOrg", a.ICPOrg, "示例公司"},
+		{"Source", a.Source, "ZoomEye"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSearchWeb(t *testing.T) {
+	s := newTestScanner(t, ResourceWeb, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/web/search" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"matches": [
+			{"site": "www.example.com:8443", "ip": ["5.6.7.8"], "title": "Login", "icp": "示例公司"},
+			{"site": "example.org", "ip": "9.9.9.9", "service": "https"}
+		]}`))
+	})
+
+	assets, err := s.Search(context.Background(), `site:"example.com"`, 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 2 {
+		t.Fatalf("len(assets) = %d, want 2", len(assets))
+	}
+	if a := assets[0]; a.Domain != "www.example.com" || a.Port != "8443" || a.IP != "5.6.7.8" ||
+		a.Service != "http" || a.Title != "Login" || a.ICPOrg != "示例公司" || a.Source != "ZoomEye-Web" {
+		t.Errorf("assets[0] = %+v", a)
+	}
+	if a := assets[1]; a.Domain != "example.org" || a.Port != "" || a.Service != "https" {
+		t.Errorf("assets[1] = %+v", a)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   cerrors.Kind
+	}{
+		{"无效的 Key", http.StatusUnauthorized, `{"error": "invalid_api_key", "message": "api key is invalid"}`, cerrors.KindAuthFailed},
+		{"额度耗尽", http.StatusForbidden, `{"error": "credits_insufficient", "message": "credits insufficient"}`, cerrors.KindQuotaExhausted},
+		{"请求过快", http.StatusOK, `{"error": "request_too_fast", "message": "slow down"}`, cerrors.KindRateLimited},
+		{"查询错误", http.StatusBadRequest, `{"error": "query_invalid", "message": "bad query"}`, cerrors.KindBadQuery},
+		{"服务端错误", http.StatusBadGateway, `<html>Bad Gateway</html>`, cerrors.KindNetwork},
+		{"无法解析的响应", http.StatusOK, `{"matches": {}}`, cerrors.KindMalformedResponse},
+	}
+	for _, tt := range tests {
+		s := newTestScanner(t, ResourceHost, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		})
+		_, err := s.Search(context.Background(), "port:22", 1, 20)
+		if got := cerrors.KindOf(err); got != tt.want {
+			t.Errorf("%s: kind = %v, want %v (err = %v)", tt.name, got, tt.want, err)
+		}
+	}
+}
+
+func TestQuota(t *testing.T) {
+	s := newTestScanner(t, ResourceWeb, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/resources-info" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"plan": "user", "quota_info": {"remain_free_quota": 100, "remain_pay_quota": 20, "remain_total_quota": 120}}`))
+	})
+	quota, err := s.Quota(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Remaining != 120 || !quota.PerResult {
+		t.Errorf("Quota() = %+v", quota)
+	}
+}